@@ -0,0 +1,115 @@
+package dux
+
+import "fmt"
+
+// AddBlueprintSource installs all blueprints found in a git repository.
+type AddBlueprintSource struct {
+	Source string // A path or URL, optionally followed by @ref
+	Force  bool   // Replace existing blueprints that have not been installed from the same repository
+}
+
+// CommandName implements Command
+func (c *AddBlueprintSource) CommandName() string { return "add-blueprint-source" }
+
+// AddBlueprintsFromGit clones a git repository and copies the
+// blueprints it contains into the store, recording the repository
+// and commit as the origin of each blueprint.
+type AddBlueprintsFromGit struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+	git    *Git
+}
+
+// NewAddBlueprintsFromGit returns a new command handler using the git executable found in $PATH.
+func NewAddBlueprintsFromGit(fs FileSystem, store Store, events EventStore) *AddBlueprintsFromGit {
+	return &AddBlueprintsFromGit{
+		fs:     fs,
+		store:  store,
+		events: events,
+		git:    NewGit(),
+	}
+}
+
+// Execute implements CommandHandler
+func (h *AddBlueprintsFromGit) Execute(command Command) error {
+	args := command.(*AddBlueprintSource)
+	source, err := ParseGitSource(args.Source)
+	if err != nil {
+		return err
+	}
+	checkout, err := checkoutBlueprints(h.git, source)
+	if err != nil {
+		return err
+	}
+	defer checkout.Close()
+
+	names, err := checkout.store.List("*")
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no blueprints found in %s", source)
+	}
+
+	for _, name := range names {
+		if err := h.checkConflict(name, source, args.Force); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		blueprint := new(Blueprint)
+		if err := checkout.store.Get(name, blueprint); err != nil {
+			return err
+		}
+		templates, err := readTemplates(checkout.fs, name)
+		if err != nil {
+			return err
+		}
+		if err := replaceTemplates(h.fs, name, templates); err != nil {
+			return err
+		}
+		if err := copySkeleton(checkout.fs, h.fs, name); err != nil {
+			return err
+		}
+		blueprint.Origin = &BlueprintOrigin{
+			URL:    source.URL,
+			Ref:    source.Ref,
+			Commit: checkout.commit,
+		}
+		if err := h.store.Put(name, blueprint); err != nil {
+			return err
+		}
+		h.events.Emit(&Event{
+			Name: "blueprint-added",
+			Payload: EventPayload{
+				"name":   name,
+				"source": source.String(),
+				"commit": checkout.commit,
+			},
+		})
+	}
+
+	return nil
+}
+
+// checkConflict returns an error if a blueprint called name exists
+// that has not been installed from source before.
+func (h *AddBlueprintsFromGit) checkConflict(name string, source *GitSource, force bool) error {
+	if force {
+		return nil
+	}
+	existing := new(Blueprint)
+	err := h.store.Get(name, existing)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Origin == nil || existing.Origin.URL != source.URL {
+		return fmt.Errorf("blueprint %q already exists", name)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package dux_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+// blueprintRepository is a bare git repository together with a working copy used for committing to it.
+type blueprintRepository struct {
+	t    *testing.T
+	dir  string
+	bare string
+	work string
+}
+
+func newBlueprintRepository(t *testing.T) *blueprintRepository {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in $PATH")
+	}
+	dir, err := ioutil.TempDir("", "dux-git")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err)
+	}
+	repo := &blueprintRepository{
+		t:    t,
+		dir:  dir,
+		bare: filepath.Join(dir, "shared.git"),
+		work: filepath.Join(dir, "work"),
+	}
+	repo.git(dir, "init", "--quiet", "--bare", repo.bare)
+	repo.git(dir, "clone", "--quiet", repo.bare, repo.work)
+	return repo
+}
+
+func (r *blueprintRepository) git(dir string, args ...string) string {
+	r.t.Helper()
+	args = append([]string{"-c", "user.name=dux", "-c", "user.email=dux@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes files into the working copy, commits them and pushes the result.
+func (r *blueprintRepository) commit(files map[string]string) string {
+	r.t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(r.work, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			r.t.Fatalf("WriteFile: %s", err)
+		}
+	}
+	r.git(r.work, "add", "-A")
+	r.git(r.work, "commit", "--quiet", "-m", "update blueprints")
+	r.git(r.work, "push", "--quiet", "origin", "HEAD")
+	return r.git(r.work, "rev-parse", "HEAD")
+}
+
+func (r *blueprintRepository) Close() {
+	os.RemoveAll(r.dir)
+}
+
+func TestAddBlueprintsFromGit_copies_blueprints_and_records_their_origin(t *testing.T) {
+	repo := newBlueprintRepository(t)
+	defer repo.Close()
+	commit := repo.commit(map[string]string{
+		"blueprints/shared.yaml":             "name: shared\nfiles:\n  out: x.tmpl\n",
+		"blueprints/shared/templates/x.tmpl": "version 1\n",
+	})
+
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(&dux.AddBlueprintSource{Source: repo.bare})
+
+	h.AssertEvent(t, app.EventStore, "blueprint-added", dux.EventPayload{"name": "shared", "commit": commit})
+	h.AssertFileContents(t, app.FileSystem, "blueprints/shared/templates/x.tmpl", "version 1\n")
+	blueprint := new(dux.Blueprint)
+	if err := app.Store.Get("shared", blueprint); err != nil {
+		t.Fatalf("Store.Get: %s", err)
+	}
+	if blueprint.Origin == nil || blueprint.Origin.URL != repo.bare || blueprint.Origin.Commit != commit {
+		t.Fatalf("Unexpected origin: %#v", blueprint.Origin)
+	}
+}
+
+func TestAddBlueprintsFromGit_refuses_to_replace_local_blueprints(t *testing.T) {
+	repo := newBlueprintRepository(t)
+	defer repo.Close()
+	repo.commit(map[string]string{"blueprints/shared.json": `{"Name":"shared"}`})
+
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("shared"))
+	err := app.Execute(&dux.AddBlueprintSource{Source: repo.bare})
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected conflict, got %v", err)
+	}
+}
+
+func TestUpdateBlueprintsFromGit_installs_newer_revisions_and_reports_template_diffs(t *testing.T) {
+	repo := newBlueprintRepository(t)
+	defer repo.Close()
+	first := repo.commit(map[string]string{
+		"blueprints/shared.json":             `{"Name":"shared"}`,
+		"blueprints/shared/templates/x.tmpl": "version 1\n",
+	})
+
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(&dux.AddBlueprintSource{Source: repo.bare})
+	second := repo.commit(map[string]string{"blueprints/shared/templates/x.tmpl": "version 2\n"})
+	do(&dux.UpdateBlueprints{})
+
+	h.AssertEvent(t, app.EventStore, "blueprint-updated", dux.EventPayload{"name": "shared", "from": first, "to": second})
+	h.AssertEvent(t, app.EventStore, "blueprint-template-changed", dux.EventPayload{
+		"blueprintName": "shared",
+		"template":      "x.tmpl",
+		"diff": `--- a/blueprints/shared/templates/x.tmpl
++++ b/blueprints/shared/templates/x.tmpl
+@@ -1,1 +1,1 @@
+-version 1
++version 2
+`,
+	})
+	h.AssertFileContents(t, app.FileSystem, "blueprints/shared/templates/x.tmpl", "version 2\n")
+}
+
+func TestParseGitSource_separates_ref_from_url(t *testing.T) {
+	for source, expected := range map[string]dux.GitSource{
+		"../shared":                         {URL: "../shared"},
+		"../shared@v1.0":                    {URL: "../shared", Ref: "v1.0"},
+		"git@example.com:team/bp.git":       {URL: "git@example.com:team/bp.git"},
+		"git@example.com:team/bp.git@main":  {URL: "git@example.com:team/bp.git", Ref: "main"},
+		"https://user@example.com/bp.git":   {URL: "https://user@example.com/bp.git"},
+		"https://example.com/bp.git@feat/x": {URL: "https://example.com/bp.git", Ref: "feat/x"},
+	} {
+		got, err := dux.ParseGitSource(source)
+		if err != nil {
+			t.Errorf("ParseGitSource(%q): %s", source, err)
+			continue
+		}
+		if *got != expected {
+			t.Errorf("ParseGitSource(%q): expected %#v, got %#v", source, expected, *got)
+		}
+	}
+}
+
+func TestParseGitSource_rejects_urls_and_refs_starting_with_a_dash(t *testing.T) {
+	for _, source := range []string{"--upload-pack=touch x", "../shared@--orphan"} {
+		if _, err := dux.ParseGitSource(source); err == nil {
+			t.Errorf("ParseGitSource(%q): expected an error", source)
+		}
+	}
+}
+
+func TestUpdateBlueprintsFromGit_rejects_origins_starting_with_a_dash(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/shared.json", `{"name":"shared","origin":{"url":"--upload-pack=touch x","commit":"0"}}`)
+
+	err := app.Execute(&dux.UpdateBlueprints{})
+
+	if err == nil || !strings.Contains(err.Error(), "must not start with") {
+		t.Fatalf("Expected the origin to be rejected, got %v", err)
+	}
+}
+
+func TestUpdateBlueprintsFromGit_returns_an_error_for_sources_that_cannot_be_fetched(t *testing.T) {
+	repo := newBlueprintRepository(t)
+	defer repo.Close()
+	repo.commit(map[string]string{"blueprints/shared.json": `{"Name":"shared"}`})
+
+	app := h.NewApp()
+	h.FailOnExecuteError(t, app)(&dux.AddBlueprintSource{Source: repo.bare})
+	os.RemoveAll(repo.bare)
+	err := app.Execute(&dux.UpdateBlueprints{})
+
+	updateErr, ok := err.(*dux.UpdateFailedError)
+	if !ok {
+		t.Fatalf("Expected *dux.UpdateFailedError, got %#v", err)
+	}
+	if len(updateErr.Errors) != 1 || !strings.Contains(updateErr.Errors[0].Error(), repo.bare) {
+		t.Fatalf("Expected one error for %s, got %v", repo.bare, updateErr.Errors)
+	}
+	h.AssertEvent(t, app.EventStore, "blueprint-update-failed", dux.EventPayload{"name": "shared"})
+}
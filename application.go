@@ -24,14 +24,25 @@ func NewApplication() *Application {
 // Init installs all default command handlers after clearing all registered command handlers.
 func (app *Application) Init() *Application {
 	app.commandHandlers = map[string]CommandHandler{}
-	app.Store = NewFileSystemStore("blueprints", app.FileSystem)
+	app.Store = NewFileSystemStore("blueprints", app.FileSystem).WithMigrations(BlueprintMigrations)
 	app.Handle("render-blueprint", NewRenderBlueprintToFileSystem(app.FileSystem, app.Store, app.EventStore))
 	app.Handle("create-blueprint", NewCreateBlueprintInFileSystem(app.Store, app.EventStore))
 	app.Handle("define-blueprint-template", NewStoreBlueprintTemplate(app.FileSystem, app.EventStore))
 	app.Handle("define-blueprint-file", NewAddFileToBlueprint(app.Store, app.EventStore))
+	app.Handle("define-blueprint-argument", NewAddArgumentToBlueprint(app.Store, app.EventStore))
 	app.Handle("describe-blueprint", NewSetBlueprintDescription(app.Store, app.EventStore))
 	app.Handle("list-templates", NewListTemplatesInFileSystem(app.FileSystem, app.EventStore))
+	app.Handle("migrate-blueprints", NewMigrateBlueprintsInStore(app.Store, app.EventStore))
+	app.Handle("export-blueprint", NewExportBlueprintToArchive(app.FileSystem, app.Store, app.EventStore))
+	app.Handle("import-blueprint", NewImportBlueprintFromArchive(app.FileSystem, app.Store, app.EventStore))
+	app.Handle("add-blueprint-source", NewAddBlueprintsFromGit(app.FileSystem, app.Store, app.EventStore))
+	app.Handle("update-blueprints", NewUpdateBlueprintsFromGit(app.FileSystem, app.Store, app.EventStore))
+	app.Handle("lint-blueprint", NewLintBlueprintInFileSystem(app.FileSystem, app.Store, app.EventStore))
+	app.Handle("test-blueprint", NewTestBlueprintWithFixtures(app.FileSystem, app.Store, app.EventStore))
+	app.Handle("define-blueprint-hook", NewAddHookToBlueprint(app.Store, app.EventStore))
+	app.Handle("format-files", NewFormatFilesInFileSystem(app.FileSystem, app.EventStore))
 	app.Handle("install", NewInstallInFileSystem(app.FileSystem, app.EventStore))
+	app.Handle("run-hooks", NewRunHooksInShell(app.FileSystem, app.Store, app.EventStore))
 	return app
 }
 
@@ -1,10 +1,280 @@
 package dux
 
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BlueprintTemplateDirectory returns the directory in which the
+// templates of the blueprint called name are stored.
+func BlueprintTemplateDirectory(name string) string {
+	return filepath.Join("blueprints", name, "templates")
+}
+
+// Actions describing how a file is generated from its template.
+const (
+	FileActionRender = "render" // The template is rendered with the blueprint's arguments
+	FileActionCopy   = "copy"   // The template is copied verbatim
+)
+
+// BlueprintSkeletonDirectory returns the directory which is rendered
+// recursively into the destination when rendering the blueprint
+// called name.
+func BlueprintSkeletonDirectory(name string) string {
+	return filepath.Join("blueprints", name, "skeleton")
+}
+
+// Argument describes a named value that is provided in the context
+// when rendering a blueprint.
+type Argument struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`        // The type of the value, "string" if empty
+	Description string `json:"description,omitempty"` // A short text explaining the purpose of the argument
+	Default     string `json:"default,omitempty"`     // The value used if none is provided
+	Required    bool   `json:"required,omitempty"`    // Whether a value needs to be provided
+
+	// Choices lists the permitted values, if the argument only
+	// accepts a fixed set of values.
+	Choices []string `json:"choices,omitempty"`
+}
+
+// Argument types that are checked by Argument.Validate.  Values of
+// any other type are accepted as is.
+//
+// Values of lists are separated by commas, values of maps are comma
+// separated KEY:VALUE pairs.
+const (
+	ArgumentTypeString     = "string"
+	ArgumentTypeIdentifier = "identifier"
+	ArgumentTypeInt        = "int"
+	ArgumentTypeBool       = "bool"
+	ArgumentTypeList       = "list"
+	ArgumentTypeMap        = "map"
+)
+
+// identifierPattern matches identifiers in any of the supported styles.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Parse validates value and converts it into the representation used
+// when rendering templates: identifiers become *Identifier, integers
+// int, booleans bool, lists []interface{} and maps
+// map[string]interface{}.  Values of other types are returned as is.
+func (arg *Argument) Parse(value string) (interface{}, error) {
+	if err := arg.Validate(value); err != nil {
+		return nil, err
+	}
+	switch arg.Type {
+	case ArgumentTypeIdentifier:
+		identifier := new(Identifier)
+		err := identifier.Set(value)
+		return identifier, err
+	case ArgumentTypeInt:
+		return strconv.Atoi(value)
+	case ArgumentTypeBool:
+		return strconv.ParseBool(value)
+	case ArgumentTypeList:
+		list := []interface{}{}
+		for _, element := range splitList(value) {
+			list = append(list, element)
+		}
+		return list, nil
+	case ArgumentTypeMap:
+		result := map[string]interface{}{}
+		for _, pair := range splitList(value) {
+			parts := strings.SplitN(pair, ":", 2)
+			result[parts[0]] = parts[1]
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+// Convert turns value into the representation used when rendering
+// templates like Parse does.  In addition to strings, value can be a
+// list of strings, for example when an argument has been given more
+// than once, or a value decoded from JSON.
+func (arg *Argument) Convert(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return arg.Parse(v)
+	case float64:
+		return arg.Parse(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		return arg.Parse(strconv.FormatBool(v))
+	case []interface{}:
+		if arg.Type != ArgumentTypeList && arg.Type != ArgumentTypeMap {
+			return nil, fmt.Errorf("%s: expected a single value", arg.Name)
+		}
+		return arg.convertEach(v)
+	}
+	return value, nil
+}
+
+// convertEach converts all string elements of values and merges the
+// results into a single list or map.
+func (arg *Argument) convertEach(values []interface{}) (interface{}, error) {
+	list := []interface{}{}
+	merged := map[string]interface{}{}
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			list = append(list, value)
+			continue
+		}
+		converted, err := arg.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		switch c := converted.(type) {
+		case []interface{}:
+			list = append(list, c...)
+		case map[string]interface{}:
+			for key, v := range c {
+				merged[key] = v
+			}
+		}
+	}
+	if arg.Type == ArgumentTypeMap {
+		return merged, nil
+	}
+	return list, nil
+}
+
+// Validate checks that value is acceptable for arg, according to its
+// type and choices.  For lists, every element is checked against the
+// choices and for maps every value.
+func (arg *Argument) Validate(value string) error {
+	switch arg.Type {
+	case ArgumentTypeList:
+		for _, element := range splitList(value) {
+			if err := arg.validateChoice(element); err != nil {
+				return err
+			}
+		}
+		return nil
+	case ArgumentTypeMap:
+		for _, pair := range splitList(value) {
+			parts := strings.SplitN(pair, ":", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("%s: %q is not a KEY:VALUE pair", arg.Name, pair)
+			}
+			if err := arg.validateChoice(parts[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := arg.validateChoice(value); err != nil {
+		return err
+	}
+
+	switch arg.Type {
+	case ArgumentTypeIdentifier:
+		if !identifierPattern.MatchString(value) {
+			return fmt.Errorf("%s: %q is not an identifier", arg.Name, value)
+		}
+	case ArgumentTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s: %q is not an integer", arg.Name, value)
+		}
+	case ArgumentTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", arg.Name, value)
+		}
+	}
+	return nil
+}
+
+// validateChoice checks that value is one of the choices of arg, if
+// there are any.
+func (arg *Argument) validateChoice(value string) error {
+	if len(arg.Choices) == 0 {
+		return nil
+	}
+	for _, choice := range arg.Choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %q is not one of %s", arg.Name, value, strings.Join(arg.Choices, ", "))
+}
+
+// splitList splits value at commas, ignoring empty elements.
+func splitList(value string) []string {
+	elements := []string{}
+	for _, element := range strings.Split(value, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			elements = append(elements, element)
+		}
+	}
+	return elements
+}
+
+// BlueprintFixtureDirectory returns the directory in which the test
+// fixtures of the blueprint called name are stored.
+func BlueprintFixtureDirectory(name string) string {
+	return filepath.Join("blueprints", name, "testdata")
+}
+
+// BlueprintOrigin describes the git repository and commit from
+// which a blueprint has been installed.
+type BlueprintOrigin struct {
+	URL    string `json:"url"`
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+}
+
 // Blueprint collects information about files to generate.
 type Blueprint struct {
-	Name        string            // The ID of the blueprint
-	Files       map[string]string // Files maps destination file names to template file names.
-	Description string            // A short text describing the purpose of the blueprint
+	Name        string            `json:"name"`        // The ID of the blueprint
+	Files       map[string]string `json:"files"`       // Files maps destination file names to template file names.
+	Description string            `json:"description"` // A short text describing the purpose of the blueprint
+
+	// Modes maps destination file names to the permissions of
+	// the generated file.  Files without an entry are created
+	// with DefaultFileMode.
+	Modes map[string]os.FileMode `json:"modes,omitempty"`
+
+	// Actions maps destination file names to FileActionCopy or
+	// FileActionRender.  Files without an entry are copied if
+	// their template is binary and rendered otherwise.
+	Actions map[string]string `json:"actions,omitempty"`
+
+	// Conditions maps destination file names to templates deciding
+	// whether the file is generated.  The file is skipped if the
+	// condition renders to an empty string, "false", "0" or
+	// "<no value>".
+	Conditions map[string]string `json:"conditions,omitempty"`
+
+	// Iterations maps destination file names to the name of a list
+	// or map in the context.  One file is generated per element,
+	// which is available to the templates as .item together with
+	// its position as .index.  Elements of maps are represented
+	// as maps with the keys "key" and "value".
+	Iterations map[string]string `json:"iterations,omitempty"`
+
+	// Arguments describes the values that are expected in the
+	// context when rendering the blueprint.
+	Arguments []*Argument `json:"arguments,omitempty"`
+
+	// PostInstall lists commands that are run in the project root
+	// after the generated files have been installed.  Every
+	// command is a template over the blueprint's context, which
+	// additionally provides the installed files as .files.
+	PostInstall []string `json:"postInstall,omitempty"`
+
+	// Origin records where the blueprint has been installed
+	// from, if it has not been created locally.
+	Origin *BlueprintOrigin `json:"origin,omitempty"`
+
+	// SchemaVersion is the version of the format in which the
+	// blueprint has been stored.  It is set by the store.
+	SchemaVersion int `json:"schemaVersion"`
 }
 
 // DefineFile adds an entry for destinationFileName into the list of
@@ -17,6 +287,169 @@ func (bp *Blueprint) DefineFile(destinationFileName, templateFileName string) *B
 	return bp
 }
 
+// SetFileMode sets the permissions of the file generated for
+// destinationFileName to mode.
+func (bp *Blueprint) SetFileMode(destinationFileName string, mode os.FileMode) *Blueprint {
+	if bp.Modes == nil {
+		bp.Modes = map[string]os.FileMode{}
+	}
+	bp.Modes[destinationFileName] = mode.Perm()
+	return bp
+}
+
+// FileMode returns the permissions of the file generated for destinationFileName.
+func (bp *Blueprint) FileMode(destinationFileName string) os.FileMode {
+	if mode, found := bp.Modes[destinationFileName]; found {
+		return mode
+	}
+	return DefaultFileMode
+}
+
+// SetFileAction sets how the file for destinationFileName is generated
+// from its template.  An empty action removes any existing action.
+func (bp *Blueprint) SetFileAction(destinationFileName, action string) *Blueprint {
+	if action == "" {
+		delete(bp.Actions, destinationFileName)
+		return bp
+	}
+	if bp.Actions == nil {
+		bp.Actions = map[string]string{}
+	}
+	bp.Actions[destinationFileName] = action
+	return bp
+}
+
+// FileAction returns how the file for destinationFileName is
+// generated, or an empty string if it depends on the contents of the
+// template.
+func (bp *Blueprint) FileAction(destinationFileName string) string {
+	return bp.Actions[destinationFileName]
+}
+
+// SetFileCondition makes generating the file for destinationFileName
+// depend on the condition template.  An empty condition removes any
+// existing condition.
+func (bp *Blueprint) SetFileCondition(destinationFileName, condition string) *Blueprint {
+	if condition == "" {
+		delete(bp.Conditions, destinationFileName)
+		return bp
+	}
+	if bp.Conditions == nil {
+		bp.Conditions = map[string]string{}
+	}
+	bp.Conditions[destinationFileName] = condition
+	return bp
+}
+
+// FileCondition returns the condition of the file generated for
+// destinationFileName, or an empty string if it is always generated.
+func (bp *Blueprint) FileCondition(destinationFileName string) string {
+	return bp.Conditions[destinationFileName]
+}
+
+// SetFileIteration generates one file for destinationFileName per
+// element of the list or map called name in the context.  An empty
+// name removes any existing iteration.
+func (bp *Blueprint) SetFileIteration(destinationFileName, name string) *Blueprint {
+	if name == "" {
+		delete(bp.Iterations, destinationFileName)
+		return bp
+	}
+	if bp.Iterations == nil {
+		bp.Iterations = map[string]string{}
+	}
+	bp.Iterations[destinationFileName] = name
+	return bp
+}
+
+// FileIteration returns the name of the value over which the file for
+// destinationFileName iterates, or an empty string if only a single
+// file is generated.
+func (bp *Blueprint) FileIteration(destinationFileName string) string {
+	return bp.Iterations[destinationFileName]
+}
+
+// DefineArgument adds arg to the blueprint's arguments, replacing
+// any existing argument with the same name.
+func (bp *Blueprint) DefineArgument(arg *Argument) *Blueprint {
+	for i, existing := range bp.Arguments {
+		if existing.Name == arg.Name {
+			bp.Arguments[i] = arg
+			return bp
+		}
+	}
+	bp.Arguments = append(bp.Arguments, arg)
+	return bp
+}
+
+// Argument returns the argument called name, or nil if the blueprint
+// does not declare such an argument.
+func (bp *Blueprint) Argument(name string) *Argument {
+	for _, arg := range bp.Arguments {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// MissingArgumentsError lists the required arguments of a blueprint
+// for which no value has been provided.
+type MissingArgumentsError struct {
+	Arguments []*Argument
+}
+
+// Error implements error
+func (e *MissingArgumentsError) Error() string {
+	lines := []string{"Missing values for arguments:"}
+	for _, arg := range e.Arguments {
+		line := "  " + arg.Name
+		if arg.Description != "" {
+			line += "  " + arg.Description
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// PrepareArguments turns data into the context for rendering the
+// blueprint: arguments without a value get their default value and
+// all values are converted using Argument.Convert.
+//
+// A *MissingArgumentsError is returned if required arguments have
+// neither a value nor a default.
+func (bp *Blueprint) PrepareArguments(data map[string]interface{}) error {
+	missing := []*Argument{}
+	for _, arg := range bp.Arguments {
+		value, found := data[arg.Name]
+		if !found {
+			if arg.Default == "" {
+				if arg.Required {
+					missing = append(missing, arg)
+				}
+				continue
+			}
+			value = arg.Default
+		}
+		converted, err := arg.Convert(value)
+		if err != nil {
+			return err
+		}
+		data[arg.Name] = converted
+	}
+	if len(missing) > 0 {
+		return &MissingArgumentsError{Arguments: missing}
+	}
+	return nil
+}
+
+// AddPostInstallHook appends command to the commands that are run
+// after installing the generated files.
+func (bp *Blueprint) AddPostInstallHook(command string) *Blueprint {
+	bp.PostInstall = append(bp.PostInstall, command)
+	return bp
+}
+
 // SetDescription updates the description of the blueprint to the provided value
 func (bp *Blueprint) SetDescription(desc string) *Blueprint {
 	bp.Description = desc
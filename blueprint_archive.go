@@ -0,0 +1,126 @@
+package dux
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// BlueprintArchiveVersion is the version of the archive format
+// produced by ExportBlueprintToArchive.
+const BlueprintArchiveVersion = 1
+
+const (
+	// archiveManifestPath is the path of the manifest inside a blueprint archive.
+	archiveManifestPath = "manifest.json"
+
+	// archiveDefinitionPath is the path of the blueprint definition inside a blueprint archive.
+	archiveDefinitionPath = "blueprint.json"
+
+	// archiveTemplatesDir is the directory holding templates inside a blueprint archive.
+	archiveTemplatesDir = "templates"
+
+	// archiveSkeletonDir is the directory holding the skeleton inside a blueprint archive.
+	archiveSkeletonDir = "skeleton"
+
+	// maxArchiveEntrySize is the maximum size of a single file in a blueprint archive.
+	maxArchiveEntrySize = 16 << 20
+
+	// maxArchiveSize is the maximum size of all files in a blueprint archive together.
+	maxArchiveSize = 64 << 20
+)
+
+// BlueprintManifest describes the contents of a blueprint archive.
+type BlueprintManifest struct {
+	Version       int                    `json:"version"`         // The version of the archive format
+	Name          string                 `json:"name"`            // The name of the archived blueprint
+	SchemaVersion int                    `json:"schemaVersion"`   // The schema version of the archived blueprint definition
+	Files         map[string]string      `json:"files"`           // Maps paths in the archive to their SHA-256 checksums
+	Modes         map[string]os.FileMode `json:"modes,omitempty"` // Maps paths in the archive to their permissions, unless they are DefaultFileMode
+	Checksum      string                 `json:"checksum"`        // Checksum over all entries in Files and Modes
+}
+
+// FileMode returns the permissions recorded for the file at p in the archive.
+func (m *BlueprintManifest) FileMode(p string) os.FileMode {
+	if mode, found := m.Modes[p]; found {
+		return mode.Perm()
+	}
+	return DefaultFileMode
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of contents.
+func fileChecksum(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// ComputeChecksum returns the checksum over the paths, checksums and
+// permissions of all files listed in the manifest.
+func (m *BlueprintManifest) ComputeChecksum() string {
+	paths := make([]string, 0, len(m.Files))
+	for p := range m.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	hash := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(hash, "%s %s\n", m.Files[p], p)
+	}
+	for _, p := range paths {
+		if mode, found := m.Modes[p]; found {
+			fmt.Fprintf(hash, "mode %o %s\n", mode, p)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Verify checks that the manifest describes exactly the given archive
+// entries and that their checksums match.
+func (m *BlueprintManifest) Verify(entries map[string][]byte) error {
+	if m.Version != BlueprintArchiveVersion {
+		return fmt.Errorf("unsupported archive version %d", m.Version)
+	}
+	if m.Checksum != m.ComputeChecksum() {
+		return fmt.Errorf("manifest checksum mismatch")
+	}
+	if _, found := m.Files[archiveDefinitionPath]; !found {
+		return fmt.Errorf("archive does not contain %s", archiveDefinitionPath)
+	}
+	for p, checksum := range m.Files {
+		contents, found := entries[p]
+		if !found {
+			return fmt.Errorf("archive is incomplete: missing %s", p)
+		}
+		if fileChecksum(contents) != checksum {
+			return fmt.Errorf("checksum mismatch for %s", p)
+		}
+	}
+	for p := range m.Modes {
+		if _, found := m.Files[p]; !found {
+			return fmt.Errorf("manifest lists permissions for unlisted file %s", p)
+		}
+	}
+	for p := range entries {
+		if _, found := m.Files[p]; !found {
+			return fmt.Errorf("archive contains unlisted file %s", p)
+		}
+	}
+	return nil
+}
+
+// cleanArchivePath normalizes name and rejects paths that are
+// absolute or would escape the directory into which the archive is
+// extracted.
+func cleanArchivePath(name string) (string, error) {
+	name = strings.Replace(name, "\\", "/", -1)
+	cleaned := path.Clean(name)
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") || cleaned == "." {
+		return "", fmt.Errorf("invalid path in archive: %q", name)
+	}
+	return cleaned, nil
+}
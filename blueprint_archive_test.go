@@ -0,0 +1,174 @@
+package dux_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func exportExampleBlueprint(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DescribeBlueprint("a", "An example"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
+	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
+	archive := new(bytes.Buffer)
+	do(h.ExportBlueprint("a", archive))
+	return archive
+}
+
+func TestImportBlueprintFromArchive_imports_exported_blueprints(t *testing.T) {
+	archive := exportExampleBlueprint(t)
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.ImportBlueprint(archive, false))
+
+	h.AssertEvent(t, app.EventStore, "blueprint-imported", dux.EventPayload{"name": "a", "templates": 1})
+	h.AssertFileContents(t, app.FileSystem, "blueprints/a/templates/x.tmpl", "{{.n}}")
+	blueprint := new(dux.Blueprint)
+	if err := app.Store.Get("a", blueprint); err != nil {
+		t.Fatalf("Store.Get: %s", err)
+	}
+	if got, want := blueprint.Description, "An example"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+}
+
+func TestImportBlueprintFromArchive_refuses_to_overwrite_existing_blueprints(t *testing.T) {
+	archive := exportExampleBlueprint(t)
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+
+	err := app.Execute(h.ImportBlueprint(bytes.NewReader(archive.Bytes()), false))
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Expected import to fail, got %v", err)
+	}
+
+	do(h.ImportBlueprint(bytes.NewReader(archive.Bytes()), true))
+	h.AssertFileContents(t, app.FileSystem, "blueprints/a/templates/x.tmpl", "{{.n}}")
+}
+
+func TestImportBlueprintFromArchive_removes_stale_templates_when_forced(t *testing.T) {
+	archive := exportExampleBlueprint(t)
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "stale.tmpl", "stale"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{"stale.txt": "stale"})
+
+	do(h.ImportBlueprint(archive, true))
+
+	h.AssertTree(t, app.FileSystem, "blueprints/a/templates", map[string]string{"x.tmpl": "{{.n}}"})
+	h.AssertTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{})
+}
+
+// rewriteArchive copies all entries of archive and passes them through change.
+func rewriteArchive(t *testing.T, archive *bytes.Buffer, change func(name string, contents []byte) (string, []byte)) *bytes.Buffer {
+	t.Helper()
+	in, err := gzip.NewReader(archive)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %s", err)
+	}
+	result := new(bytes.Buffer)
+	compressed := gzip.NewWriter(result)
+	out := tar.NewWriter(compressed)
+	reader := tar.NewReader(in)
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			break
+		}
+		contents := new(bytes.Buffer)
+		contents.ReadFrom(reader)
+		name, data := change(header.Name, contents.Bytes())
+		if name == "" {
+			continue
+		}
+		out.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))})
+		out.Write(data)
+	}
+	out.Close()
+	compressed.Close()
+	return result
+}
+
+func TestImportBlueprintFromArchive_rejects_incomplete_archives(t *testing.T) {
+	archive := rewriteArchive(t, exportExampleBlueprint(t), func(name string, contents []byte) (string, []byte) {
+		if name == "templates/x.tmpl" {
+			return "", nil
+		}
+		return name, contents
+	})
+	app := h.NewApp()
+	err := app.Execute(h.ImportBlueprint(archive, false))
+	if err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Fatalf("Expected import to fail, got %v", err)
+	}
+	if exists, _ := app.Store.Exists("a"); exists {
+		t.Fatalf("Expected blueprint not to be imported")
+	}
+}
+
+func TestImportBlueprintFromArchive_rejects_paths_escaping_the_blueprint_directory(t *testing.T) {
+	archive := rewriteArchive(t, exportExampleBlueprint(t), func(name string, contents []byte) (string, []byte) {
+		if name == "templates/x.tmpl" {
+			return "templates/../../../x.tmpl", contents
+		}
+		return name, contents
+	})
+	app := h.NewApp()
+	err := app.Execute(h.ImportBlueprint(archive, false))
+	if err == nil || !strings.Contains(err.Error(), "invalid path") {
+		t.Fatalf("Expected import to fail, got %v", err)
+	}
+}
+
+func TestImportBlueprintFromArchive_restores_file_permissions(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{"bin/run": "#!/bin/sh\n", "README": "About\n"})
+	if err := app.FileSystem.Chmod("blueprints/a/skeleton/bin/run", dux.ExecutableFileMode); err != nil {
+		t.Fatalf("Chmod: %s", err)
+	}
+	archive := new(bytes.Buffer)
+	do(h.ExportBlueprint("a", archive))
+
+	imported := h.NewApp()
+	h.FailOnExecuteError(t, imported)(h.ImportBlueprint(archive, false))
+	for filename, want := range map[string]os.FileMode{
+		"blueprints/a/skeleton/bin/run": dux.ExecutableFileMode,
+		"blueprints/a/skeleton/README":  dux.DefaultFileMode,
+	} {
+		info, err := imported.FileSystem.Stat(filename)
+		if err != nil {
+			t.Fatalf("Stat: %s", err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Errorf("Expected %s to have mode %o, got %o", filename, want, got)
+		}
+	}
+}
+
+func TestImportBlueprintFromArchive_rejects_oversized_entries(t *testing.T) {
+	archive := rewriteArchive(t, exportExampleBlueprint(t), func(name string, contents []byte) (string, []byte) {
+		if name == "templates/x.tmpl" {
+			return name, make([]byte, 17<<20)
+		}
+		return name, contents
+	})
+	app := h.NewApp()
+	err := app.Execute(h.ImportBlueprint(archive, false))
+	if err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Fatalf("Expected import to fail, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package dux
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// blueprintCheckout provides access to the blueprints contained in a
+// local checkout of a git repository.
+type blueprintCheckout struct {
+	dir    string
+	commit string
+	fs     FileSystem
+	store  Store
+}
+
+// checkoutBlueprints clones source into a temporary directory.  The
+// checkout needs to be removed by calling Close.
+func checkoutBlueprints(git *Git, source *GitSource) (*blueprintCheckout, error) {
+	dir, err := ioutil.TempDir("", "dux-checkout")
+	if err != nil {
+		return nil, err
+	}
+	repository := filepath.Join(dir, "repository")
+	commit, err := git.Checkout(source, repository)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	fs := NewOnDiskFileSystem(repository)
+	return &blueprintCheckout{
+		dir:    dir,
+		commit: commit,
+		fs:     fs,
+		store:  NewFileSystemStore("blueprints", fs).WithMigrations(BlueprintMigrations),
+	}, nil
+}
+
+// Close removes the checkout from disk.
+func (c *blueprintCheckout) Close() error {
+	return os.RemoveAll(c.dir)
+}
+
+// readTemplates returns the contents of all templates of the named
+// blueprint, indexed by their path relative to the template
+// directory.
+func readTemplates(fs FileSystem, blueprintName string) (map[string]string, error) {
+	return readTree(fs, BlueprintTemplateDirectory(blueprintName))
+}
+
+// readTree returns the contents of all files below dir, indexed by
+// their path relative to dir.  If dir does not exist, an empty map is
+// returned.
+func readTree(fs FileSystem, dir string) (map[string]string, error) {
+	files := map[string]string{}
+	if exists, err := fs.Exists(dir); err != nil || !exists {
+		return files, err
+	}
+
+	err := fs.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		f, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		contents, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		files[relative] = string(contents)
+		return nil
+	})
+	return files, err
+}
+
+// replaceTree makes the files below dir match files, removing files
+// that are not present in files.
+func replaceTree(fs FileSystem, dir string, files map[string]string) error {
+	existing, err := readTree(fs, dir)
+	if err != nil {
+		return err
+	}
+	for name := range existing {
+		if _, keep := files[name]; keep {
+			continue
+		}
+		if err := fs.Remove(filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	for name, contents := range files {
+		out, err := fs.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, strings.NewReader(contents))
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceTemplates makes the templates of the named blueprint in fs
+// match templates, removing templates that are not present in
+// templates.
+func replaceTemplates(fs FileSystem, blueprintName string, templates map[string]string) error {
+	return replaceTree(fs, BlueprintTemplateDirectory(blueprintName), templates)
+}
+
+// copySkeleton makes the skeleton of the named blueprint in to match
+// the skeleton found in from.
+func copySkeleton(from, to FileSystem, blueprintName string) error {
+	dir := BlueprintSkeletonDirectory(blueprintName)
+	files, err := readTree(from, dir)
+	if err != nil {
+		return err
+	}
+	return replaceTree(to, dir, files)
+}
+
+// templateNames returns the union of the keys of all maps in lexical order.
+func templateNames(templates ...map[string]string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, t := range templates {
+		for name := range t {
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,167 @@
+package dux
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FixtureResult describes the outcome of rendering a blueprint with
+// the input of a test fixture.
+type FixtureResult struct {
+	Fixture  string            // The name of the fixture
+	Rendered map[string]string // The files produced by rendering the blueprint
+	Expected map[string]string // The files expected by the fixture
+	Errors   []error           // Errors that occurred while rendering
+}
+
+// Passed returns true if rendering succeeded and produced exactly the expected files.
+func (r *FixtureResult) Passed() bool {
+	return len(r.Errors) == 0 && r.Diff() == ""
+}
+
+// Diff returns a unified diff between the expected and the rendered files.
+func (r *FixtureResult) Diff() string {
+	out := new(bytes.Buffer)
+	for _, name := range templateNames(r.Expected, r.Rendered) {
+		expected, wasExpected := r.Expected[name]
+		rendered, wasRendered := r.Rendered[name]
+		oldName, newName := "expected/"+filepath.ToSlash(name), "rendered/"+filepath.ToSlash(name)
+		if !wasExpected {
+			oldName = "/dev/null"
+		}
+		if !wasRendered {
+			newName = "/dev/null"
+		}
+		if wasExpected && wasRendered && expected == rendered {
+			continue
+		}
+		diff := Diff(oldName, newName, expected, rendered)
+		if diff == "" {
+			// an empty file is missing on one side
+			diff = "--- " + oldName + "\n+++ " + newName + "\n"
+		}
+		out.WriteString(diff)
+	}
+	return out.String()
+}
+
+// fixtureExpectedDir returns the directory holding the expected output of a fixture.
+func fixtureExpectedDir(blueprintName, fixture string) string {
+	return filepath.Join(BlueprintFixtureDirectory(blueprintName), fixture, "expected")
+}
+
+// BlueprintFixtures returns the names of all test fixtures of the
+// named blueprint in lexical order.
+//
+// Every directory directly below the blueprint's fixture directory
+// is a fixture.  A fixture contains the arguments for rendering the
+// blueprint in a file called "input" with any extension supported
+// by FileSystemStore, and the expected output in a directory called
+// "expected".
+func BlueprintFixtures(fs FileSystem, blueprintName string) ([]string, error) {
+	fixtures := []string{}
+	dir := BlueprintFixtureDirectory(blueprintName)
+	if exists, err := fs.Exists(dir); err != nil || !exists {
+		return fixtures, err
+	}
+
+	err := fs.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !strings.ContainsRune(relative, filepath.Separator) {
+			fixtures = append(fixtures, relative)
+		}
+		return filepath.SkipDir
+	})
+	return fixtures, err
+}
+
+// RunBlueprintFixture renders the named blueprint, as found in store
+// and fs, with the input of fixture into an InMemoryFileSystem and
+// compares the result against the expected output of the fixture.
+// Arguments missing from the input get their default values and
+// rendered files are formatted, like they are by 'dux new'.
+func RunBlueprintFixture(fs FileSystem, store Store, blueprintName, fixture string) (*FixtureResult, error) {
+	blueprint := new(Blueprint)
+	if err := store.Get(blueprintName, blueprint); err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{}
+	input := NewFileSystemStore(filepath.Join(BlueprintFixtureDirectory(blueprintName), fixture), fs)
+	if err := input.Get("input", &data); err != nil && !IsNotFound(err) {
+		return nil, err
+	}
+	expected, err := readTree(fs, fixtureExpectedDir(blueprintName, fixture))
+	if err != nil {
+		return nil, err
+	}
+	templates, err := readTemplates(fs, blueprintName)
+	if err != nil {
+		return nil, err
+	}
+
+	sandbox := NewInMemoryFileSystem()
+	if err := replaceTemplates(sandbox, blueprintName, templates); err != nil {
+		return nil, err
+	}
+	if err := copySkeleton(fs, sandbox, blueprintName); err != nil {
+		return nil, err
+	}
+	sandboxStore := NewInMemoryStore()
+	if err := sandboxStore.Put(blueprintName, blueprint); err != nil {
+		return nil, err
+	}
+
+	result := &FixtureResult{
+		Fixture:  fixture,
+		Rendered: map[string]string{},
+		Expected: expected,
+		Errors:   []error{},
+	}
+	if err := blueprint.PrepareArguments(data); err != nil {
+		result.Errors = append(result.Errors, err)
+		return result, nil
+	}
+	events := NewTransientEventStore()
+	events.Subscribe(func(e *Event) {
+		if e.Error != nil {
+			result.Errors = append(result.Errors, e.Error)
+		}
+	})
+	rendered := []string{}
+	events.Subscribe(func(e *Event) {
+		if copied, _ := e.Payload["copied"].(bool); e.Name == "template-rendered" && !copied {
+			rendered = append(rendered, e.Payload["filename"].(string))
+		}
+	})
+	render := NewRenderBlueprintToFileSystem(sandbox, sandboxStore, events)
+	if err := render.Execute(&RenderBlueprint{Name: blueprintName, Destination: "output", Data: data}); err != nil {
+		// Failures of single files have already been recorded
+		if _, ok := err.(*RenderFailedError); !ok {
+			result.Errors = append(result.Errors, err)
+		}
+	}
+	format := NewFormatFilesInFileSystem(sandbox, events)
+	if err := format.Execute(&FormatFiles{Files: rendered}); err != nil {
+		result.Errors = append(result.Errors, err)
+	}
+
+	result.Rendered, err = readTree(sandbox, "output")
+	return result, err
+}
+
+// UpdateBlueprintFixture replaces the expected output of the fixture
+// described by result with the rendered output.
+func UpdateBlueprintFixture(fs FileSystem, blueprintName string, result *FixtureResult) error {
+	return replaceTree(fs, fixtureExpectedDir(blueprintName, result.Fixture), result.Rendered)
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dhamidi/dux"
+)
+
+// argumentValues collects the values for the arguments of a blueprint
+// given on the command line.
+type argumentValues struct {
+	data  map[string]interface{}
+	given map[string]bool
+}
+
+// newArgumentValues returns an empty collection of values.
+func newArgumentValues() *argumentValues {
+	return &argumentValues{
+		data:  map[string]interface{}{},
+		given: map[string]bool{},
+	}
+}
+
+// Assign stores value under key.  Dotted keys like "db.table" create
+// nested maps.  Assigning to the same key more than once builds a list
+// of all values.
+func (v *argumentValues) Assign(key string, value interface{}) {
+	path := strings.Split(key, ".")
+	data := v.data
+	for _, name := range path[:len(path)-1] {
+		child, ok := data[name].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			data[name] = child
+		}
+		data = child
+	}
+
+	name := path[len(path)-1]
+	if !v.given[key] {
+		data[name] = value
+	} else if list, ok := data[name].([]interface{}); ok {
+		data[name] = append(list, value)
+	} else {
+		data[name] = []interface{}{data[name], value}
+	}
+	v.given[key] = true
+}
+
+// Load merges the JSON object stored in filename into the values.
+// Values given on the command line take precedence.
+func (v *argumentValues) Load(fs dux.FileSystem, filename string) error {
+	contents, err := dux.ReadFile(fs, filename)
+	if err != nil {
+		return err
+	}
+	loaded := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &loaded); err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	for key, value := range loaded {
+		if _, found := v.data[key]; !found {
+			v.data[key] = value
+		}
+	}
+	return nil
+}
+
+// argumentFlag implements flag.Value for a blueprint argument, storing
+// validated values as strings.
+type argumentFlag struct {
+	arg    *dux.Argument
+	values *argumentValues
+}
+
+// String implements flag.Value
+func (f *argumentFlag) String() string {
+	if f.arg == nil {
+		return ""
+	}
+	return f.arg.Default
+}
+
+// Set implements flag.Value
+func (f *argumentFlag) Set(value string) error {
+	if err := f.arg.Validate(value); err != nil {
+		return err
+	}
+	f.values.Assign(f.arg.Name, value)
+	return nil
+}
+
+// IsBoolFlag makes boolean arguments usable as --NAME without a value.
+func (f *argumentFlag) IsBoolFlag() bool { return f.arg.Type == dux.ArgumentTypeBool }
+
+// aliasFlag is an alternative spelling of an argument flag.  Aliases
+// are accepted on the command line, but not listed in the usage.
+type aliasFlag struct {
+	*argumentFlag
+}
+
+// defineArgumentFlags adds a flag for every argument of blueprint to
+// flags.  Underscores in argument names are spelled as dashes, e.g.
+// with_tests becomes --with-tests, but the name of the argument is
+// accepted as well.  Names already taken by a flag are skipped, the
+// argument can still be passed as NAME=VALUE.
+func defineArgumentFlags(flags *flag.FlagSet, blueprint *dux.Blueprint, values *argumentValues) {
+	for _, arg := range blueprint.Arguments {
+		description := arg.Description
+		if len(arg.Choices) > 0 {
+			description += fmt.Sprintf(" (one of %s)", strings.Join(arg.Choices, ", "))
+		}
+		value := &argumentFlag{arg: arg, values: values}
+		defined := false
+		for _, name := range []string{strings.Replace(arg.Name, "_", "-", -1), arg.Name} {
+			if flags.Lookup(name) != nil {
+				continue
+			}
+			if defined {
+				flags.Var(&aliasFlag{value}, name, description)
+				continue
+			}
+			flags.Var(value, name, description)
+			defined = true
+		}
+	}
+}
+
+// blueprintSpec describes how to pass the arguments of blueprint on the
+// command line.  Required arguments can be passed positionally.
+func blueprintSpec(blueprint *dux.Blueprint) *Spec {
+	spec := &Spec{
+		Name:        blueprint.Name,
+		Summary:     blueprint.Description,
+		Description: blueprint.Description,
+	}
+	for _, arg := range blueprint.Arguments {
+		if !arg.Required {
+			continue
+		}
+		spec.Arguments = append(spec.Arguments, &ArgumentSpec{
+			Name:        strings.ToUpper(arg.Name),
+			Description: arg.Description,
+			Optional:    true,
+		})
+	}
+	return spec
+}
+
+// parseBlueprintArgs collects the values for the arguments of
+// blueprint from args.  Args may contain the flags defined in flags,
+// assignments like NAME=VALUE, JSON values like NAME:=JSON, files
+// containing a JSON object like @FILE and values for required
+// arguments in the order in which they have been declared.
+//
+// Values given as strings have been validated.
+func parseBlueprintArgs(fs dux.FileSystem, blueprint *dux.Blueprint, flags *flag.FlagSet, values *argumentValues, args []string) error {
+	positional := []string{}
+	for {
+		if err := flags.Parse(args); err == flag.ErrHelp {
+			return err
+		} else if err != nil {
+			return &UsageError{Err: err}
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	files := []string{}
+	remaining := []string{}
+	for _, arg := range positional {
+		parts := strings.SplitN(arg, "=", 2)
+		switch {
+		case strings.HasPrefix(arg, "@"):
+			files = append(files, arg[1:])
+		case len(parts) == 1:
+			remaining = append(remaining, arg)
+		case strings.HasSuffix(parts[0], ":"):
+			var value interface{}
+			if err := json.Unmarshal([]byte(parts[1]), &value); err != nil {
+				return &ValidationError{Err: fmt.Errorf("%s: %s", strings.TrimSuffix(parts[0], ":"), err)}
+			}
+			values.Assign(strings.TrimSuffix(parts[0], ":"), value)
+		default:
+			if arg := blueprint.Argument(parts[0]); arg != nil {
+				if err := arg.Validate(parts[1]); err != nil {
+					return &ValidationError{Err: err}
+				}
+			}
+			values.Assign(parts[0], parts[1])
+		}
+	}
+
+	for _, arg := range blueprint.Arguments {
+		if len(remaining) == 0 {
+			break
+		}
+		if _, found := values.data[arg.Name]; found || !arg.Required {
+			continue
+		}
+		if err := arg.Validate(remaining[0]); err != nil {
+			return &ValidationError{Err: err}
+		}
+		values.Assign(arg.Name, remaining[0])
+		remaining = remaining[1:]
+	}
+	if len(remaining) > 0 {
+		return usageErrorf("Too many arguments: %s", strings.Join(remaining, " "))
+	}
+
+	for _, filename := range files {
+		if err := values.Load(fs, filename); err != nil {
+			return &ValidationError{Err: err}
+		}
+	}
+	return nil
+}
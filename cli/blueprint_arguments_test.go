@@ -0,0 +1,159 @@
+package cli_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func writeFile(t *testing.T, fs dux.FileSystem, filename, contents string) {
+	t.Helper()
+	f, err := fs.Create(filename)
+	if err != nil {
+		t.Fatalf("Create %q: %s", filename, err)
+	}
+	defer f.Close()
+	fmt.Fprint(f, contents)
+}
+
+func TestCLI_Run_accepts_blueprint_arguments_as_flags(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Required: true}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Choices: []string{"Hello", "Goodbye"}, Default: "Hello"}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--name", "world", "--greeting=Goodbye", "--dry-run")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, ".dux/world.txt", "Goodbye, world!")
+	if _, err := app.FileSystem.Open("world.txt"); err == nil {
+		t.Fatalf("Expected world.txt not to be installed with --dry-run")
+	}
+}
+
+func TestCLI_Run_assigns_positional_values_to_required_arguments(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Required: true}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Required: true}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "Hello", "world")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world!")
+}
+
+func TestCLI_Run_rejects_too_many_positional_values(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Required: true}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "world", "Hello")
+
+	h.AssertExitStatus(t, result, cli.ExitUsage)
+}
+
+func TestCLI_Run_converts_blueprint_arguments_to_their_type(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Type: dux.ArgumentTypeIdentifier, Required: true}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "loud", Type: dux.ArgumentTypeBool}),
+	)
+	app.Execute(h.DefineBlueprintTemplate("a", "greeting.txt", `{{ if .loud }}HELLO{{ else }}Hello{{ end }}, {{ .name.ToSnake.Lower }}!`))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--name", "WideWorld", "--loud")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "WideWorld.txt", "HELLO, wide_world!")
+}
+
+func TestCLI_Run_shows_usage_of_blueprint(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Required: true, Description: "Who to greet"}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Choices: []string{"Hello", "Goodbye"}}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--help")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	for _, expected := range []string{
+		"Usage: dux new a [OPTIONS] [NAME]",
+		"Who to greet",
+		"(one of Hello, Goodbye)",
+		"--dry-run",
+	} {
+		if !strings.Contains(result.Stdout, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result.Stdout)
+		}
+	}
+}
+
+func TestCLI_Run_builds_nested_data_from_arguments(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "fields", Type: dux.ArgumentTypeMap}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "tags", Type: dux.ArgumentTypeList}),
+	)
+	app.Execute(h.DefineBlueprintTemplate("a", "greeting.txt",
+		`{{ .db.table }}:{{ range $k, $v := .fields }} {{ $k }}={{ $v }}{{ end }};{{ range .tags }} {{ . }}{{ end }}; {{ .limit }}`))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=users",
+		"db.table=users", "fields=name:string,age:int", "tags=a,b", "tags=c", "limit:=10")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "users.txt", "users: age=int name=string; a b c; 10")
+}
+
+func TestCLI_Run_loads_arguments_from_json_file(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	app := h.NewAppWithBlueprint(t, fs, "{{ .greeting }}, {{ .name }}!")
+	h.FailOnExecuteError(t, app)(&dux.DefineBlueprintArgument{
+		BlueprintName: "a",
+		Argument:      &dux.Argument{Name: "count", Type: dux.ArgumentTypeInt},
+	})
+	app.Execute(h.DefineBlueprintTemplate("a", "greeting.txt", "{{ .greeting }}, {{ .name }} x{{ .count }}!"))
+	writeFile(t, fs, "context.json", `{"greeting": "Hello", "name": "file", "count": 2}`)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "@context.json", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world x2!")
+}
+
+func TestCLI_Run_rejects_invalid_json_values(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name:={")
+
+	h.AssertExitStatus(t, result, cli.ExitInvalid)
+}
+
+func TestCLI_Run_accepts_dashes_in_flags_for_arguments(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Required: true}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "with_tests", Type: dux.ArgumentTypeBool}),
+	)
+	app.Execute(h.DefineBlueprintTemplate("a", "greeting.txt", `{{ .name }}{{ if .with_tests }} with tests{{ end }}`))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--name", "Foo", "--with-tests")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "Foo.txt", "Foo with tests")
+}
+
+func TestCLI_Run_lists_flags_for_arguments_with_dashes(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "with_tests", Type: dux.ArgumentTypeBool, Description: "Add tests"}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--help")
+
+	if !strings.Contains(result.Stdout, "--with-tests") {
+		t.Errorf("Expected --with-tests in output:\n%s", result.Stdout)
+	}
+	if strings.Contains(result.Stdout, "--with_tests") {
+		t.Errorf("Expected --with_tests not to be listed:\n%s", result.Stdout)
+	}
+}
@@ -1,8 +1,12 @@
 package cli
 
 import (
+	"bufio"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 
 	"github.com/dhamidi/dux"
@@ -16,32 +20,135 @@ type CLI struct {
 	out   io.Writer
 	Err   io.Writer
 	depth int
+
+	// OutputFormat selects how read-only commands present their
+	// results, one of OutputText, OutputJSON or OutputYAML.
+	OutputFormat string
+
+	// NoInput disables prompting for missing values.
+	NoInput bool
+
+	terminal *bool         // whether the user can answer prompts
+	lines    *bufio.Reader // buffers in for reading answers to prompts
+
+	events *EventRenderer
+}
+
+// Option configures a CLI when passed to NewCLI.
+type Option func(cli *CLI)
+
+// WithInput makes the CLI read user input from in.
+func WithInput(in io.Reader) Option {
+	return func(cli *CLI) { cli.in = in }
+}
+
+// WithOutput makes the CLI write regular output to out.
+func WithOutput(out io.Writer) Option {
+	return func(cli *CLI) { cli.out = out }
+}
+
+// WithErrorOutput makes the CLI write errors and diagnostics to err.
+func WithErrorOutput(err io.Writer) Option {
+	return func(cli *CLI) { cli.Err = err }
+}
+
+// WithTerminal overrides whether the CLI's input and error output are
+// considered to be a terminal, allowing it to prompt for missing values.
+func WithTerminal(terminal bool) Option {
+	return func(cli *CLI) { cli.terminal = &terminal }
 }
 
 // NewCLI creates a new CLI application wrapping the provided dux
 // instance and connected to os.Stdout, os.Stderr and os.Stdin by
 // default.
-func NewCLI(app *dux.Application) *CLI {
-	return &CLI{
+//
+// Use options to connect the CLI to other readers and writers.
+func NewCLI(app *dux.Application, options ...Option) *CLI {
+	cli := &CLI{
 		app:   app,
 		in:    os.Stdin,
 		out:   os.Stdout,
 		Err:   os.Stderr,
 		depth: 0,
+
+		OutputFormat: OutputText,
+	}
+	for _, option := range options {
+		option(cli)
 	}
+	if cli.terminal == nil {
+		terminal := isTerminal(cli.in) && isTerminal(cli.Err)
+		cli.terminal = &terminal
+	}
+	cli.lines = bufio.NewReader(cli.in)
+	cli.events = NewEventRenderer(cli.out, cli.Err)
+	return cli
 }
 
+// DefineFlags adds the global options of the CLI to flags.
+func (cli *CLI) DefineFlags(flags *flag.FlagSet) {
+	cli.OutputFormat = OutputText
+	flags.Var(&outputFormatFlag{format: &cli.OutputFormat}, "output", "Show results as text, json or yaml")
+	flags.BoolVar(&cli.NoInput, "no-input", false, "Never prompt for missing values")
+	cli.events.DefineFlags(flags)
+}
+
+// Events returns the renderer used for showing events to the user.
+func (cli *CLI) Events() *EventRenderer { return cli.events }
+
 // Execute runs a given command with the given arguments.  Any errors returned by the command are shown to the user
+//
+// Flags are parsed using the command's Options.  If the command
+// declares its arguments through HasSpec, they are validated before
+// running the command.  Passing -h or --help shows the usage of the
+// command instead of running it.
 func (cli *CLI) Execute(cmd Command, args []string) (Command, error) {
 	options := cmd.Options()
-	if options != nil {
-		options.Parse(args)
-		args = options.Args()
+	if options == nil {
+		options = flag.NewFlagSet("", flag.ContinueOnError)
+	}
+	options.SetOutput(ioutil.Discard)
+	options.Usage = func() {}
+	if err := options.Parse(args); err == flag.ErrHelp {
+		ShowUsage(cli.out, cmd)
+		return cmd, nil
+	} else if err != nil {
+		return cmd, &UsageError{Err: err}
+	}
+	args = options.Args()
+	if spec, ok := cmd.(HasSpec); ok {
+		if err := spec.Spec().Validate(args); err != nil {
+			return cmd, err
+		}
 	}
 	return cmd.Exec(cli, args)
 }
 
+// Run executes cmd with args like Execute and returns the exit code
+// for the outcome.  Events emitted while cmd is running are shown to
+// the user, followed by a summary.  Errors are shown to the user, and
+// usage errors together with the usage of the failing command.
+func (cli *CLI) Run(cmd Command, args []string) int {
+	unsubscribe := cli.app.EventStore.Subscribe(cli.ShowEvent)
+	failed, err := cli.Execute(cmd, args)
+	unsubscribe()
+	cli.events.Summary()
+	if err != nil {
+		cli.ShowError(err)
+		var usageErr *UsageError
+		if errors.As(err, &usageErr) {
+			ShowUsage(cli.Err, failed)
+		}
+	}
+	return ExitCode(err)
+}
+
 // ShowError displays an error to the user
 func (cli *CLI) ShowError(err error) {
 	fmt.Fprintf(cli.Err, "Error: %s\n", err)
 }
+
+// ShowEvent displays an event to the user.
+func (cli *CLI) ShowEvent(e *dux.Event) {
+	cli.events.Render(e)
+}
@@ -0,0 +1,206 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func newDispatcher() *cli.DispatchCommand {
+	return cli.NewDispatchCommand("dux").
+		Add("new", cli.NewCommandNew()).
+		Add("list", cli.NewCommandList()).
+		Add("blueprint", cli.NewDispatchCommand("blueprint").
+			Add("lint", cli.NewCommandBlueprintLint()))
+}
+
+func TestCLI_Run_captures_output_of_successful_commands(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "list")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	if !strings.Contains(result.Stdout, "# A test") {
+		t.Fatalf("Expected description in output, got %q", result.Stdout)
+	}
+}
+
+func TestCLI_Run_installs_rendered_files(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world!")
+	if !strings.Contains(result.Stdout, "create  world.txt") {
+		t.Fatalf("Expected status line in output, got %q", result.Stdout)
+	}
+}
+
+func TestCLI_Run_exits_with_install_error_on_conflicts(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+	h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+	app.Execute(h.DefineBlueprintTemplate("a", "greeting.txt", "Goodbye, {{ .name }}!"))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitInstallFailed)
+	if !strings.Contains(result.Stdout, "conflict  world.txt") {
+		t.Fatalf("Expected status line in output, got %q", result.Stdout)
+	}
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world!")
+	h.AssertTree(t, app.FileSystem, ".dux", map[string]string{})
+}
+
+func TestCLI_Run_exits_with_usage_error_for_unknown_commands(t *testing.T) {
+	app := h.NewApp()
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "unknown")
+
+	h.AssertExitStatus(t, result, cli.ExitUsage)
+	if !strings.Contains(result.Stderr, `Unknown command: "unknown"`) {
+		t.Fatalf("Expected error on stderr, got %q", result.Stderr)
+	}
+	if !strings.Contains(result.Stderr, "Available commands:") {
+		t.Fatalf("Expected usage on stderr, got %q", result.Stderr)
+	}
+}
+
+func TestCLI_Run_exits_with_usage_error_for_unknown_flags(t *testing.T) {
+	app := h.NewApp()
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "--no-such-flag", "a")
+
+	h.AssertExitStatus(t, result, cli.ExitUsage)
+	if result.Stdout != "" {
+		t.Fatalf("Expected no output on stdout, got %q", result.Stdout)
+	}
+}
+
+func TestCLI_Run_exits_with_validation_error_for_lint_problems(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!",
+		h.DefineBlueprintFile("a", "{{ .name }}.md", "missing.txt"),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "blueprint", "lint", "a")
+
+	h.AssertExitStatus(t, result, cli.ExitInvalid)
+}
+
+func TestCLI_Run_exits_with_render_error_when_rendering_fails(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name.first }}!")
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitRenderFailed)
+	if exists, _ := app.FileSystem.Exists("world.txt"); exists {
+		t.Fatalf("Expected nothing to be installed")
+	}
+}
+
+// brokenFile returns the commands for adding a file to blueprint "a"
+// which cannot be rendered.
+func brokenFile() []dux.Command {
+	return []dux.Command{
+		h.DefineBlueprintTemplate("a", "broken.txt", "Hello, {{ .name.first }}!"),
+		h.DefineBlueprintFile("a", "broken.txt", "broken.txt"),
+	}
+}
+
+func TestCLI_Run_installs_nothing_if_any_file_fails_to_render(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!", brokenFile()...)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitRenderFailed)
+	if exists, _ := app.FileSystem.Exists("world.txt"); exists {
+		t.Fatalf("Expected world.txt not to be installed")
+	}
+	for _, staged := range []string{".dux/world.txt", ".dux/broken.txt"} {
+		if exists, _ := app.FileSystem.Exists(staged); exists {
+			t.Errorf("Expected %s to be removed", staged)
+		}
+	}
+}
+
+func TestCLI_Run_installs_successfully_rendered_files_with_keep_going(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!", brokenFile()...)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--keep-going", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitRenderFailed)
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world!")
+	if exists, _ := app.FileSystem.Exists("broken.txt"); exists {
+		t.Fatalf("Expected broken.txt not to be installed")
+	}
+}
+
+func TestCLI_Run_exits_with_install_error_when_installing_fails(t *testing.T) {
+	fs := h.NewFailingFileSystem(dux.NewInMemoryFileSystem())
+	fs.Fail("rename", ".dux/world.txt")
+	app := h.NewAppWithBlueprint(t, fs, "Hello, {{ .name }}!")
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitInstallFailed)
+	h.AssertTree(t, app.FileSystem, ".dux", map[string]string{})
+}
+
+func TestCLI_Run_formats_generated_go_files(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "",
+		h.DefineBlueprintTemplate("a", "main.go", "package {{ .name }}\nvar  x =  1\n"),
+		h.DefineBlueprintFile("a", "{{ .name }}.go", "main.go"),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=main")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "main.go", "package main\n\nvar x = 1\n")
+}
+
+func TestCLI_Run_does_not_install_files_that_cannot_be_formatted(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "",
+		h.DefineBlueprintTemplate("a", "main.go", "package {{ .name }}\nvar x = (\n"),
+		h.DefineBlueprintFile("a", "{{ .name }}.go", "main.go"),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=main")
+
+	h.AssertExitStatus(t, result, cli.ExitRenderFailed)
+	if _, err := app.FileSystem.Open("main.go"); err == nil {
+		t.Fatalf("Expected main.go not to be installed")
+	}
+	if exists, _ := app.FileSystem.Exists(".dux/main.go"); exists {
+		t.Fatalf("Expected .dux/main.go to be removed")
+	}
+	if !strings.Contains(result.Stderr, "var x = (") {
+		t.Errorf("Expected offending line in output:\n%s", result.Stderr)
+	}
+}
+
+func TestCLI_Run_installs_unformatted_files_with_no_format(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "",
+		h.DefineBlueprintTemplate("a", "main.go", "package {{ .name }}\nvar  x =  1\n"),
+		h.DefineBlueprintFile("a", "{{ .name }}.go", "main.go"),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--no-format", "name=main")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "main.go", "package main\nvar  x =  1\n")
+}
+
+func TestCLI_Run_does_not_format_files_copied_verbatim(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "")
+	do := h.FailOnExecuteError(t, app)
+	do(h.DefineBlueprintTemplate("a", "asset.go", "package {{ .name }}\nvar  x = (\n"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "asset.go", TemplateName: "asset.go", Action: dux.FileActionCopy})
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=main")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "asset.go", "package {{ .name }}\nvar  x = (\n")
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintAdd is a CLI command for installing blueprints from a git repository.
+type CommandBlueprintAdd struct {
+	*parentCommand
+
+	Force bool
+}
+
+// NewCommandBlueprintAdd creates a new, empty instance of this command.
+func NewCommandBlueprintAdd() *CommandBlueprintAdd {
+	return &CommandBlueprintAdd{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintAdd) Exec(ctx *CLI, args []string) (Command, error) {
+	return cmd, ctx.app.Execute(&dux.AddBlueprintSource{
+		Source: args[0],
+		Force:  cmd.Force,
+	})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintAdd) Options() *flag.FlagSet {
+	flags := flag.NewFlagSet("blueprint add", flag.ContinueOnError)
+	flags.BoolVar(&cmd.Force, "force", false, "Replace existing blueprints")
+	return flags
+}
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintAdd) Spec() *Spec {
+	return &Spec{
+		Name:    "add",
+		Summary: "Install blueprints from a git repository",
+		Description: `Clone REPOSITORY, which can be a path or a URL, and copy all blueprints found in its
+blueprints directory.  If REF is given, blueprints are copied from that branch, tag or commit.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "REPOSITORY[@REF]", Description: "Path or URL of a git repository, optionally followed by a branch, tag or commit"},
+		},
+	}
+}
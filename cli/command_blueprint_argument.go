@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintArgument is a CLI command for declaring blueprint arguments.
+type CommandBlueprintArgument struct {
+	*parentCommand
+
+	Argument *dux.Argument
+}
+
+// NewCommandBlueprintArgument creates a new, empty instance of this command.
+func NewCommandBlueprintArgument() *CommandBlueprintArgument {
+	return &CommandBlueprintArgument{
+		parentCommand: new(parentCommand),
+		Argument:      new(dux.Argument),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintArgument) Exec(ctx *CLI, args []string) (Command, error) {
+	cmd.Argument.Name = args[1]
+
+	return cmd, ctx.app.Execute(&dux.DefineBlueprintArgument{
+		BlueprintName: args[0],
+		Argument:      cmd.Argument,
+	})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintArgument) Options() *flag.FlagSet {
+	flags := flag.NewFlagSet("blueprint argument", flag.ContinueOnError)
+	flags.StringVar(&cmd.Argument.Type, "type", "", "Type of the argument: string, identifier, int, bool, list or map")
+	flags.StringVar(&cmd.Argument.Description, "description", "", "Description of the argument")
+	flags.StringVar(&cmd.Argument.Default, "default", "", "Default value of the argument")
+	flags.BoolVar(&cmd.Argument.Required, "required", false, "Require a value for the argument")
+	flags.Var((*choicesValue)(&cmd.Argument.Choices), "choices", "Comma separated list of permitted values")
+	return flags
+}
+
+// choicesValue implements flag.Value for a comma separated list.
+type choicesValue []string
+
+// String implements flag.Value
+func (v *choicesValue) String() string { return strings.Join(*v, ",") }
+
+// Set implements flag.Value
+func (v *choicesValue) Set(s string) error {
+	*v = strings.Split(s, ",")
+	return nil
+}
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintArgument) Spec() *Spec {
+	return &Spec{
+		Name:        "argument",
+		Summary:     "Declare an argument of a blueprint",
+		Description: `Declare that BLUEPRINT expects a value called NAME when being rendered.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+			{Name: "NAME", Description: "Name of the argument"},
+		},
+	}
+}
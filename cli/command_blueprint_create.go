@@ -2,8 +2,6 @@ package cli
 
 import (
 	"flag"
-	"fmt"
-	"io"
 
 	"github.com/dhamidi/dux"
 )
@@ -22,10 +20,6 @@ func NewCommandBlueprintCreate() *CommandBlueprintCreate {
 
 // Exec implements Command
 func (cmd *CommandBlueprintCreate) Exec(ctx *CLI, args []string) (Command, error) {
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No blueprint name given")
-	}
-
 	err := ctx.app.Execute(&dux.CreateBlueprint{
 		Name: args[0],
 	})
@@ -41,12 +35,14 @@ func (cmd *CommandBlueprintCreate) Options() *flag.FlagSet {
 	return nil
 }
 
-// Description implements HasDescription
-func (cmd *CommandBlueprintCreate) Description() string { return `Create a new blueprint` }
-
-// ShowUsage implements HasUsage
-func (cmd *CommandBlueprintCreate) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: %s create NAME\n\n", cmd.CommandPath())
-	fmt.Fprintf(out, "Create a new blueprint called NAME")
-	fmt.Fprintf(out, "\n")
+// Spec implements HasSpec
+func (cmd *CommandBlueprintCreate) Spec() *Spec {
+	return &Spec{
+		Name:        "create",
+		Summary:     "Create a new blueprint",
+		Description: `Create a new blueprint called NAME.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "NAME", Description: "Name of the new blueprint"},
+		},
+	}
 }
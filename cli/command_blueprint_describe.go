@@ -2,8 +2,6 @@ package cli
 
 import (
 	"flag"
-	"fmt"
-	"io"
 
 	"github.com/dhamidi/dux"
 )
@@ -22,10 +20,6 @@ func NewCommandBlueprintDescribe() *CommandBlueprintDescribe {
 
 // Exec implements Command
 func (cmd *CommandBlueprintDescribe) Exec(ctx *CLI, args []string) (Command, error) {
-	if len(args) < 2 {
-		return cmd, fmt.Errorf("missing arguments")
-	}
-
 	blueprintName := args[0]
 	description := args[1]
 
@@ -42,14 +36,15 @@ func (cmd *CommandBlueprintDescribe) Options() *flag.FlagSet {
 	return nil
 }
 
-// Description implements HasDescription
-func (cmd *CommandBlueprintDescribe) Description() string {
-	return `Set the description for a blueprint`
-}
-
-// ShowUsage implements HasUsage
-func (cmd *CommandBlueprintDescribe) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: %s describe BLUEPRINT DESCRIPTION\n\n", cmd.CommandPath())
-	fmt.Fprintf(out, "Set the description for BLUEPRINT to DESCRIPTION\n")
-	fmt.Fprintf(out, "\n")
+// Spec implements HasSpec
+func (cmd *CommandBlueprintDescribe) Spec() *Spec {
+	return &Spec{
+		Name:        "describe",
+		Summary:     "Set the description for a blueprint",
+		Description: `Set the description for BLUEPRINT to DESCRIPTION.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+			{Name: "DESCRIPTION", Description: "Text describing the blueprint"},
+		},
+	}
 }
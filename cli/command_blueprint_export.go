@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintExport is a CLI command for writing a blueprint archive to stdout.
+type CommandBlueprintExport struct {
+	*parentCommand
+}
+
+// NewCommandBlueprintExport creates a new, empty instance of this command.
+func NewCommandBlueprintExport() *CommandBlueprintExport {
+	return &CommandBlueprintExport{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintExport) Exec(ctx *CLI, args []string) (Command, error) {
+	return cmd, ctx.app.Execute(&dux.ExportBlueprint{
+		BlueprintName: args[0],
+		Destination:   ctx.out,
+	})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintExport) Options() *flag.FlagSet { return nil }
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintExport) Spec() *Spec {
+	return &Spec{
+		Name:    "export",
+		Summary: "Export a blueprint as an archive",
+		Description: `Write BLUEPRINT, its templates and a manifest as a gzip compressed tar archive to stdout, e.g.
+
+  dux blueprint export BLUEPRINT > BLUEPRINT.tar.gz`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+		},
+	}
+}
@@ -2,62 +2,80 @@ package cli
 
 import (
 	"flag"
-	"fmt"
-	"io"
+	"os"
 
 	"github.com/dhamidi/dux"
 )
 
 // CommandBlueprintFile is a CLI command for rendering a blueprint.
 type CommandBlueprintFile struct {
+	*parentCommand
+
 	BlueprintName string
 	TemplateName  string
 	FileName      string
+	Executable    bool
+	Action        string
+	Condition     string
+	Each          string
 }
 
 // NewCommandBlueprintFile creates a new, empty instance of this command.
 func NewCommandBlueprintFile() *CommandBlueprintFile {
-	return &CommandBlueprintFile{}
+	return &CommandBlueprintFile{
+		parentCommand: new(parentCommand),
+	}
 }
 
-// Description implements HasDescription
-func (cmd *CommandBlueprintFile) Description() string {
-	return `Associate file with template in blueprint`
-}
+// Spec implements HasSpec
+func (cmd *CommandBlueprintFile) Spec() *Spec {
+	return &Spec{
+		Name:    "file",
+		Summary: "Associate file with template in blueprint",
+		Description: `Define FILENAME to be generated from TEMPLATE in BLUEPRINT.
+
+Binary templates are copied verbatim, all others are rendered.  Use --action
+to always copy or always render the template.
+
+Use --if to generate the file only if a condition template renders to a true
+value and --each to generate one file per element of a list or map, which is
+available as .item and its position as .index.
 
-// ShowUsage implements HasUsage
-func (cmd *CommandBlueprintFile) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: file BLUEPRINT FILENAME TEMPLATE\n\n")
-	fmt.Fprintf(out, "Define FILENAME to be generated from TEMPLATE in BLUEPRINT\n\n")
-	fmt.Fprintf(out, "Run\n\n")
-	fmt.Fprintf(out, "  blueprint show BLUEPRINT\n\n")
-	fmt.Fprintf(out, "to see possible values for TEMPLATE\n\n")
+Run 'dux blueprint show BLUEPRINT' to see possible values for TEMPLATE.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+			{Name: "FILENAME", Description: "Destination of the file, which may contain template actions"},
+			{Name: "TEMPLATE", Description: "Name of the template rendering the file", Complete: CompleteTemplates},
+		},
+	}
 }
 
 // Exec implements Command
 func (cmd *CommandBlueprintFile) Exec(ctx *CLI, args []string) (Command, error) {
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No blueprint name provided")
-	}
-	cmd.BlueprintName = args[0]
-	args = args[1:]
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No file name provided")
-	}
-	cmd.FileName = args[0]
+	cmd.BlueprintName, cmd.FileName, cmd.TemplateName = args[0], args[1], args[2]
 
-	args = args[1:]
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No template name provided")
+	mode := os.FileMode(0)
+	if cmd.Executable {
+		mode = dux.ExecutableFileMode
 	}
-	cmd.TemplateName = args[0]
 
 	return cmd, ctx.app.Execute(&dux.DefineBlueprintFile{
 		BlueprintName: cmd.BlueprintName,
 		TemplateName:  cmd.TemplateName,
 		FileName:      cmd.FileName,
+		Mode:          mode,
+		Action:        cmd.Action,
+		Condition:     cmd.Condition,
+		Each:          cmd.Each,
 	})
 }
 
 // Options implements Command
-func (cmd *CommandBlueprintFile) Options() *flag.FlagSet { return nil }
+func (cmd *CommandBlueprintFile) Options() *flag.FlagSet {
+	flags := flag.NewFlagSet("blueprint file", flag.ContinueOnError)
+	flags.BoolVar(&cmd.Executable, "executable", false, "Mark the generated file as executable")
+	flags.StringVar(&cmd.Action, "action", "", "Whether to copy or render the template")
+	flags.StringVar(&cmd.Condition, "if", "", "Only generate the file if this template renders to a true value")
+	flags.StringVar(&cmd.Each, "each", "", "Generate one file per element of the named list or map")
+	return flags
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintHook is a CLI command for adding post install hooks
+// to a blueprint.
+type CommandBlueprintHook struct {
+	*parentCommand
+}
+
+// NewCommandBlueprintHook creates a new, empty instance of this command.
+func NewCommandBlueprintHook() *CommandBlueprintHook {
+	return &CommandBlueprintHook{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintHook) Exec(ctx *CLI, args []string) (Command, error) {
+	return cmd, ctx.app.Execute(&dux.DefineBlueprintHook{
+		BlueprintName: args[0],
+		Command:       args[1],
+	})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintHook) Options() *flag.FlagSet {
+	return nil
+}
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintHook) Spec() *Spec {
+	return &Spec{
+		Name:    "hook",
+		Summary: "Run a command after installing files",
+		Description: `Add COMMAND to the commands that 'dux new BLUEPRINT' runs in the project root
+after installing the generated files, for example:
+
+  dux blueprint hook command 'gofmt -w {{.files}}'
+
+COMMAND is a template with access to the arguments of BLUEPRINT.  The installed
+files are available as .files, which prints as a list of quoted file names.
+All other values are quoted for the shell as well, so that every value is
+passed as a single word.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+			{Name: "COMMAND", Description: "Shell command to run"},
+		},
+	}
+}
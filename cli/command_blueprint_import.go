@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"flag"
+	"os"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintImport is a CLI command for installing a blueprint from an archive.
+type CommandBlueprintImport struct {
+	*parentCommand
+
+	Force bool
+}
+
+// NewCommandBlueprintImport creates a new, empty instance of this command.
+func NewCommandBlueprintImport() *CommandBlueprintImport {
+	return &CommandBlueprintImport{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintImport) Exec(ctx *CLI, args []string) (Command, error) {
+	source := ctx.in
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return cmd, err
+		}
+		defer f.Close()
+		source = f
+	}
+
+	return cmd, ctx.app.Execute(&dux.ImportBlueprint{
+		Source: source,
+		Force:  cmd.Force,
+	})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintImport) Options() *flag.FlagSet {
+	flags := flag.NewFlagSet("blueprint import", flag.ContinueOnError)
+	flags.BoolVar(&cmd.Force, "force", false, "Overwrite an existing blueprint")
+	return flags
+}
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintImport) Spec() *Spec {
+	return &Spec{
+		Name:        "import",
+		Summary:     "Import a blueprint from an archive",
+		Description: `Import the blueprint contained in the archive FILE, which has been created by export.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "FILE", Description: "Path to the archive, or - to read the archive from stdin"},
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"flag"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintLint is a CLI command for checking a blueprint for problems.
+type CommandBlueprintLint struct {
+	*parentCommand
+}
+
+// NewCommandBlueprintLint creates a new, empty instance of this command.
+func NewCommandBlueprintLint() *CommandBlueprintLint {
+	return &CommandBlueprintLint{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintLint) Exec(ctx *CLI, args []string) (Command, error) {
+	output := &LintOutput{
+		SchemaVersion: OutputSchemaVersion,
+		Blueprint:     args[0],
+		Problems:      []*LintProblemOutput{},
+	}
+	done := ctx.app.EventStore.Subscribe(func(e *dux.Event) {
+		if e.Name != "blueprint-lint-problem" {
+			return
+		}
+		problem := e.Payload["problem"].(*dux.LintProblem)
+		output.Problems = append(output.Problems, &LintProblemOutput{
+			File:    problem.File,
+			Line:    problem.Line,
+			Kind:    problem.Kind,
+			Message: problem.Message,
+		})
+	})
+	err := ctx.app.Execute(&dux.LintBlueprint{BlueprintName: args[0]})
+	done()
+	if err != nil {
+		return cmd, err
+	}
+	if err := ctx.Show(output); err != nil {
+		return cmd, err
+	}
+	if problems := len(output.Problems); problems > 0 {
+		return cmd, validationErrorf("%d problem(s) found in blueprint %q", problems, args[0])
+	}
+
+	return cmd, nil
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintLint) Options() *flag.FlagSet { return nil }
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintLint) Spec() *Spec {
+	return &Spec{
+		Name:    "lint",
+		Summary: "Check a blueprint for problems",
+		Description: `Parse all templates and destination file names of BLUEPRINT and report
+missing or unused templates, undeclared variables, unknown functions,
+duplicate destinations and destinations outside of the project.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+		},
+	}
+}
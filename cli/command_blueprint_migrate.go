@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintMigrate is a CLI command for upgrading stored blueprints to the latest format.
+type CommandBlueprintMigrate struct {
+	*parentCommand
+}
+
+// NewCommandBlueprintMigrate creates a new, empty instance of this command.
+func NewCommandBlueprintMigrate() *CommandBlueprintMigrate {
+	return &CommandBlueprintMigrate{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintMigrate) Exec(ctx *CLI, args []string) (Command, error) {
+	return cmd, ctx.app.Execute(&dux.MigrateBlueprints{})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintMigrate) Options() *flag.FlagSet { return nil }
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintMigrate) Spec() *Spec {
+	return &Spec{
+		Name:    "migrate",
+		Summary: "Rewrite all blueprints in the latest format",
+		Description: fmt.Sprintf("Load every stored blueprint, upgrade it to schema version %d and store it again.",
+			dux.LatestBlueprintSchemaVersion),
+	}
+}
@@ -3,7 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
-	"io"
+	"sort"
 
 	"github.com/dhamidi/dux"
 )
@@ -24,61 +24,83 @@ func NewCommandBlueprintShow() *CommandBlueprintShow {
 
 // Exec implements Command
 func (cmd *CommandBlueprintShow) Exec(ctx *CLI, args []string) (Command, error) {
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No blueprint name provided")
-	}
 	cmd.BlueprintName = args[0]
 	blueprint := new(dux.Blueprint)
 	if err := ctx.app.Store.Get(cmd.BlueprintName, blueprint); err != nil {
 		return cmd, fmt.Errorf("Failed to load blueprint %q", cmd.BlueprintName)
 	}
 
-	cmd.Show(ctx, blueprint)
-	return cmd, nil
+	return cmd, cmd.Show(ctx, blueprint)
 }
 
 // Options implements Command
 func (cmd *CommandBlueprintShow) Options() *flag.FlagSet { return nil }
 
 // Show displays a blueprint in the given context
-func (cmd *CommandBlueprintShow) Show(ctx *CLI, blueprint *dux.Blueprint) {
-	templateNames := []string{}
+func (cmd *CommandBlueprintShow) Show(ctx *CLI, blueprint *dux.Blueprint) error {
+	output := &BlueprintOutput{
+		SchemaVersion: OutputSchemaVersion,
+		Name:          blueprint.Name,
+		Description:   blueprint.Description,
+		Arguments:     []*BlueprintArgumentOutput{},
+		Files:         []*BlueprintFileOutput{},
+		Templates:     []string{},
+		PostInstall:   blueprint.PostInstall,
+	}
 	done := ctx.app.EventStore.Subscribe(func(e *dux.Event) {
 		if e.Name != "blueprint-template-found" {
 			return
 		}
-		templateNames = append(templateNames, e.Payload["name"].(string))
+		output.Templates = append(output.Templates, e.Payload["name"].(string))
 	})
 	ctx.app.Execute(&dux.ListTemplates{BlueprintName: blueprint.Name})
 	done()
 
-	fmt.Fprintf(ctx.out, "Name: %s\n", blueprint.Name)
-	if len(blueprint.Description) > 0 {
-		fmt.Fprintf(ctx.out, "Description: %s\n", blueprint.Description)
-	}
-	if len(blueprint.Files) > 0 {
-		fmt.Fprintf(ctx.out, "Files:\n")
-		for destination, template := range blueprint.Files {
-			fmt.Fprintf(ctx.out, "  - name: %s\n", destination)
-			fmt.Fprintf(ctx.out, "    template: %s\n", template)
+	if origin := blueprint.Origin; origin != nil {
+		output.Origin = &BlueprintOriginOutput{
+			URL:    origin.URL,
+			Ref:    origin.Ref,
+			Commit: origin.Commit,
 		}
-		fmt.Fprintf(ctx.out, "\n")
 	}
-
-	if len(templateNames) > 0 {
-		fmt.Fprintf(ctx.out, "Templates:\n")
-		for _, name := range templateNames {
-			fmt.Fprintf(ctx.out, "  - %s\n", name)
-		}
+	for _, arg := range blueprint.Arguments {
+		output.Arguments = append(output.Arguments, &BlueprintArgumentOutput{
+			Name:        arg.Name,
+			Type:        arg.Type,
+			Description: arg.Description,
+			Default:     arg.Default,
+			Required:    arg.Required,
+			Choices:     arg.Choices,
+		})
+	}
+	destinations := []string{}
+	for destination := range blueprint.Files {
+		destinations = append(destinations, destination)
+	}
+	sort.Strings(destinations)
+	for _, destination := range destinations {
+		output.Files = append(output.Files, &BlueprintFileOutput{
+			Name:      destination,
+			Template:  blueprint.Files[destination],
+			Mode:      fileModeOutput(blueprint.FileMode(destination)),
+			Action:    blueprint.FileAction(destination),
+			Condition: blueprint.FileCondition(destination),
+			Each:      blueprint.FileIteration(destination),
+		})
 	}
-}
 
-// Description implements HasDescription
-func (cmd *CommandBlueprintShow) Description() string { return `Show blueprint definition` }
+	return ctx.Show(output)
+}
 
-// ShowUsage implements HasUsage
-func (cmd *CommandBlueprintShow) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: %s show BLUEPRINT\n\n", cmd.CommandPath())
-	fmt.Fprintf(out, "Show details about a blueprint\n\n")
-	fmt.Fprintf(out, "\n")
+// Spec implements HasSpec
+func (cmd *CommandBlueprintShow) Spec() *Spec {
+	return &Spec{
+		Name:    "show",
+		Summary: "Show blueprint definition",
+		Description: `Show details about a blueprint.  Use --output json or --output yaml
+for machine-readable output (see BlueprintOutput).`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+		},
+	}
 }
@@ -2,8 +2,6 @@ package cli
 
 import (
 	"flag"
-	"fmt"
-	"io"
 	"io/ioutil"
 
 	"github.com/dhamidi/dux"
@@ -27,15 +25,7 @@ func NewCommandBlueprintTemplate() *CommandBlueprintTemplate {
 
 // Exec implements Command
 func (cmd *CommandBlueprintTemplate) Exec(ctx *CLI, args []string) (Command, error) {
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No blueprint name provided")
-	}
-	cmd.BlueprintName = args[0]
-	args = args[1:]
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No template name provided")
-	}
-	cmd.TemplateName = args[0]
+	cmd.BlueprintName, cmd.TemplateName = args[0], args[1]
 
 	if err := cmd.ReadContents(ctx); err != nil {
 		return cmd, err
@@ -48,17 +38,19 @@ func (cmd *CommandBlueprintTemplate) Exec(ctx *CLI, args []string) (Command, err
 	})
 }
 
-// Description implements HasDescription
-func (cmd *CommandBlueprintTemplate) Description() string { return `Define a template for a blueprint` }
+// Spec implements HasSpec
+func (cmd *CommandBlueprintTemplate) Spec() *Spec {
+	return &Spec{
+		Name:    "template",
+		Summary: "Define a template for a blueprint",
+		Description: `Adds a template called TEMPLATE-NAME to BLUEPRINT.
 
-// ShowUsage implements HasUsage
-func (cmd *CommandBlueprintTemplate) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: %s template [--contents='...'] BLUEPRINT TEMPLATE-NAME\n\n", cmd.CommandPath())
-	fmt.Fprintf(out, "Adds a template called TEMPLATE-NAME to BLUEPRINT.\n\n")
-	fmt.Fprintf(out, "If no template content is provided via the contents option, the template content is read from stdin.\n\n")
-	fmt.Fprintf(out, "Options:\n")
-	fmt.Fprintf(out, "  --contents='...'   Set template content")
-	fmt.Fprintf(out, "\n")
+If no template content is provided via the contents option, the template content is read from stdin.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+			{Name: "TEMPLATE-NAME", Description: "Name of the template", Complete: CompleteTemplates},
+		},
+	}
 }
 
 // Options implements Command
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintTest is a CLI command for running the test fixtures of a blueprint.
+type CommandBlueprintTest struct {
+	*parentCommand
+
+	Update bool
+}
+
+// NewCommandBlueprintTest creates a new, empty instance of this command.
+func NewCommandBlueprintTest() *CommandBlueprintTest {
+	return &CommandBlueprintTest{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintTest) Exec(ctx *CLI, args []string) (Command, error) {
+	failed := 0
+	done := ctx.app.EventStore.Subscribe(func(e *dux.Event) {
+		switch e.Name {
+		case "blueprint-fixture-passed":
+			fmt.Fprintf(ctx.out, "PASS %s\n", e.Payload["fixture"])
+		case "blueprint-fixture-updated":
+			fmt.Fprintf(ctx.out, "UPDATE %s\n", e.Payload["fixture"])
+		case "blueprint-fixture-failed":
+			failed++
+			result := e.Payload["result"].(*dux.FixtureResult)
+			fmt.Fprintf(ctx.out, "FAIL %s\n", result.Fixture)
+			for _, err := range result.Errors {
+				fmt.Fprintf(ctx.out, "  %s\n", err)
+			}
+			fmt.Fprintf(ctx.out, "%s", e.Payload["diff"])
+		}
+	})
+	err := ctx.app.Execute(&dux.TestBlueprint{
+		BlueprintName: args[0],
+		Update:        cmd.Update,
+	})
+	done()
+	if err != nil {
+		return cmd, err
+	}
+	if failed > 0 {
+		return cmd, fmt.Errorf("%d fixture(s) failed", failed)
+	}
+
+	return cmd, nil
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintTest) Options() *flag.FlagSet {
+	flags := flag.NewFlagSet("blueprint test", flag.ContinueOnError)
+	flags.BoolVar(&cmd.Update, "update", false, "Rewrite the expected files of all fixtures")
+	return flags
+}
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintTest) Spec() *Spec {
+	return &Spec{
+		Name:    "test",
+		Summary: "Run the test fixtures of a blueprint",
+		Description: `Render BLUEPRINT for every fixture in blueprints/BLUEPRINT/testdata and compare the
+result with the fixture's expected directory.  Each fixture is a directory
+containing the arguments in input.json, input.yaml or input.toml.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+		},
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dhamidi/dux"
+)
+
+// CommandBlueprintUpdate is a CLI command for updating blueprints installed from git repositories.
+type CommandBlueprintUpdate struct {
+	*parentCommand
+}
+
+// NewCommandBlueprintUpdate creates a new, empty instance of this command.
+func NewCommandBlueprintUpdate() *CommandBlueprintUpdate {
+	return &CommandBlueprintUpdate{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandBlueprintUpdate) Exec(ctx *CLI, args []string) (Command, error) {
+	done := ctx.app.EventStore.Subscribe(func(e *dux.Event) {
+		if e.Name != "blueprint-template-changed" {
+			return
+		}
+		fmt.Fprintf(ctx.out, "%s", e.Payload["diff"])
+	})
+	defer done()
+
+	return cmd, ctx.app.Execute(&dux.UpdateBlueprints{Names: args})
+}
+
+// Options implements Command
+func (cmd *CommandBlueprintUpdate) Options() *flag.FlagSet { return nil }
+
+// Spec implements HasSpec
+func (cmd *CommandBlueprintUpdate) Spec() *Spec {
+	return &Spec{
+		Name:    "update",
+		Summary: "Update blueprints installed from git repositories",
+		Description: `Install the latest revision of each BLUEPRINT from the repository it has been added from
+and show the changes made to its templates.  If no BLUEPRINT is given, all blueprints are updated.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of a blueprint to update", Optional: true, Repeated: true, Complete: CompleteBlueprints},
+		},
+	}
+}
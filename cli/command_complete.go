@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+)
+
+// CommandComplete is a hidden CLI command used by shell completion
+// scripts to find candidates for the word being completed.
+type CommandComplete struct {
+	*parentCommand
+}
+
+// NewCommandComplete creates a new, empty instance of this command.
+func NewCommandComplete() *CommandComplete {
+	return &CommandComplete{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command by printing one candidate per line for the
+// last entry in args, treating args as the words following the
+// program name on the command line.
+//
+// Callers pass "--" before the words, so that flags on the command
+// line are not interpreted as flags of this command.
+func (cmd *CommandComplete) Exec(ctx *CLI, args []string) (Command, error) {
+	root := cmd.root()
+	if root == nil {
+		return cmd, fmt.Errorf("%s has not been added to a dispatcher", cmd.Spec().Name)
+	}
+	for _, candidate := range Complete(ctx, root, args) {
+		fmt.Fprintf(ctx.out, "%s\n", candidate)
+	}
+	return cmd, nil
+}
+
+// Options implements Command
+func (cmd *CommandComplete) Options() *flag.FlagSet {
+	return nil
+}
+
+// Spec implements HasSpec
+func (cmd *CommandComplete) Spec() *Spec {
+	return &Spec{
+		Name:    "__complete",
+		Summary: "Complete a command line",
+		Arguments: []*ArgumentSpec{
+			{Name: "WORD", Description: "Words on the command line, ending with the word to complete", Optional: true, Repeated: true},
+		},
+	}
+}
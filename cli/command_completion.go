@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// completionScripts contains shell code for registering completions
+// of the program PROGRAM with bash, zsh and fish.
+var completionScripts = map[string]string{
+	"bash": `_PROGRAM_complete() {
+	local IFS=$'\n'
+	COMPREPLY=( $("${COMP_WORDS[0]}" __complete -- "${COMP_WORDS[@]:1:COMP_CWORD}") )
+	if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == *= ]]; then
+		compopt -o nospace
+	fi
+}
+complete -o default -F _PROGRAM_complete PROGRAM
+`,
+	"zsh": `#compdef PROGRAM
+_PROGRAM() {
+	local -a candidates
+	candidates=("${(@f)$("${words[1]}" __complete -- "${(@)words[2,$CURRENT]}")}")
+	compadd -S '' -- ${(M)candidates:#*=}
+	compadd -- ${candidates:#*=}
+}
+compdef _PROGRAM PROGRAM
+`,
+	"fish": `function __PROGRAM_complete
+	set -l words (commandline -opc) (commandline -ct)
+	$words[1] __complete -- $words[2..-1]
+end
+complete -c PROGRAM -f -a '(__PROGRAM_complete)'
+`,
+}
+
+// CommandCompletion is a CLI command printing shell completion scripts.
+type CommandCompletion struct {
+	*parentCommand
+}
+
+// NewCommandCompletion creates a new, empty instance of this command.
+func NewCommandCompletion() *CommandCompletion {
+	return &CommandCompletion{
+		parentCommand: new(parentCommand),
+	}
+}
+
+// Exec implements Command
+func (cmd *CommandCompletion) Exec(ctx *CLI, args []string) (Command, error) {
+	script, found := completionScripts[args[0]]
+	if !found {
+		return cmd, usageErrorf("Unsupported shell: %q", args[0])
+	}
+	program := "dux"
+	if root := cmd.root(); root != nil {
+		program = filepath.Base(root.name)
+	}
+	fmt.Fprint(ctx.out, strings.Replace(script, "PROGRAM", program, -1))
+	return cmd, nil
+}
+
+// Options implements Command
+func (cmd *CommandCompletion) Options() *flag.FlagSet {
+	return nil
+}
+
+// Spec implements HasSpec
+func (cmd *CommandCompletion) Spec() *Spec {
+	return &Spec{
+		Name:    "completion",
+		Summary: "Print a shell completion script",
+		Description: `Print a script enabling completion of commands, blueprints, templates and
+blueprint arguments in SHELL.  For example, add this line to ~/.bashrc:
+
+  source <(dux completion bash)`,
+		Arguments: []*ArgumentSpec{
+			{Name: "SHELL", Description: "One of bash, zsh or fish", Complete: completeShells},
+		},
+	}
+}
+
+// completeShells is a Completer returning the supported shells.
+func completeShells(ctx *CLI, args []string) []string {
+	shells := []string{}
+	for shell := range completionScripts {
+		shells = append(shells, shell)
+	}
+	return shells
+}
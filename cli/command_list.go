@@ -2,8 +2,6 @@ package cli
 
 import (
 	"flag"
-	"fmt"
-	"io"
 
 	"github.com/dhamidi/dux"
 )
@@ -27,37 +25,24 @@ func (cmd *CommandList) Exec(ctx *CLI, args []string) (Command, error) {
 		return cmd, err
 	}
 
-	longestBlueprintName := ""
-	for _, blueprintName := range blueprints {
-		if len(longestBlueprintName) < len(blueprintName) {
-			longestBlueprintName = blueprintName
-		}
+	output := &BlueprintListOutput{
+		SchemaVersion: OutputSchemaVersion,
+		Blueprints:    []*BlueprintSummaryOutput{},
 	}
-
-	for _, blueprint := range blueprints {
-		cmd.listBlueprint(ctx, blueprint, len(longestBlueprintName))
+	for _, blueprintName := range blueprints {
+		output.Blueprints = append(output.Blueprints, cmd.summarize(ctx, blueprintName))
 	}
-	return cmd, nil
+	return cmd, ctx.Show(output)
 }
 
-// listBlueprint displays information about a single blueprint
-func (cmd *CommandList) listBlueprint(ctx *CLI, blueprintName string, labelWidth int) {
-	if labelWidth < 20 {
-		labelWidth = 20
-	}
-	entryFormat := fmt.Sprintf("%%-%ds", labelWidth)
+// summarize collects information about a single blueprint
+func (cmd *CommandList) summarize(ctx *CLI, blueprintName string) *BlueprintSummaryOutput {
+	summary := &BlueprintSummaryOutput{Name: blueprintName}
 	blueprint := new(dux.Blueprint)
-	description := ""
-	err := ctx.app.Store.Get(blueprintName, blueprint)
-	if err == nil {
-		description = blueprint.Description
+	if err := ctx.app.Store.Get(blueprintName, blueprint); err == nil {
+		summary.Description = blueprint.Description
 	}
-
-	fmt.Fprintf(ctx.out, entryFormat, blueprintName)
-	if len(description) > 0 {
-		fmt.Fprintf(ctx.out, " # %s", description)
-	}
-	fmt.Fprintf(ctx.out, "\n")
+	return summary
 }
 
 // Options implements Command
@@ -65,12 +50,12 @@ func (cmd *CommandList) Options() *flag.FlagSet {
 	return nil
 }
 
-// Description implements HasDescription
-func (cmd *CommandList) Description() string { return `List available blueprints` }
-
-// ShowUsage implements HasUsage
-func (cmd *CommandList) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: %s list\n\n", cmd.CommandPath())
-	fmt.Fprintf(out, "List available blueprints\n")
-	fmt.Fprintf(out, "\n")
+// Spec implements HasSpec
+func (cmd *CommandList) Spec() *Spec {
+	return &Spec{
+		Name:    "list",
+		Summary: "List available blueprints",
+		Description: `List available blueprints.  Use --output json or --output yaml
+for machine-readable output (see BlueprintListOutput).`,
+	}
 }
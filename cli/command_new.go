@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/dhamidi/dux"
@@ -15,6 +17,10 @@ type CommandNew struct {
 	BlueprintName string
 	Destination   string
 	DryRun        bool
+	Force         bool
+	NoHooks       bool
+	NoFormat      bool
+	KeepGoing     bool
 }
 
 // NewCommandNew creates a new, empty instance of this command.
@@ -24,76 +30,249 @@ func NewCommandNew() *CommandNew {
 
 // Exec implements Command
 func (cmd *CommandNew) Exec(ctx *CLI, args []string) (Command, error) {
-	if len(args) == 0 {
-		return cmd, fmt.Errorf("No blueprint name provided")
-	}
 	cmd.BlueprintName = args[0]
-	data := cmd.parseData(args[1:])
-	sources := []string{}
-	destinations := []string{}
-	done := ctx.app.EventStore.Subscribe(cmd.collectRenderedFiles(&sources, &destinations))
+	blueprint := new(dux.Blueprint)
+	if err := ctx.app.Store.Get(cmd.BlueprintName, blueprint); err != nil {
+		return cmd, err
+	}
+	values := newArgumentValues()
+	err := parseBlueprintArgs(ctx.app.FileSystem, blueprint, cmd.blueprintFlags(blueprint, values), values, args[1:])
+	if err == flag.ErrHelp {
+		cmd.ShowBlueprintUsage(ctx.out, blueprint)
+		return cmd, nil
+	}
+	if err != nil {
+		return cmd, err
+	}
+	data := values.data
+	if err := ctx.ResolveArguments(blueprint, data); err != nil {
+		return cmd, err
+	}
+
+	files := new(renderedFiles)
+	renderFailures := []error{}
+	done := ctx.app.EventStore.Subscribe(files.collect)
 
-	if err := ctx.app.Execute(&dux.RenderBlueprint{
+	err = ctx.app.Execute(&dux.RenderBlueprint{
 		Name:        cmd.BlueprintName,
 		Destination: ".dux",
 		Data:        data,
-	}); err != nil {
-		return cmd, err
+	})
+	if renderErr, failed := err.(*dux.RenderFailedError); failed {
+		renderFailures, err = renderErr.Errors, nil
+	}
+	if err == nil && !cmd.NoFormat {
+		stopCollectingFailures := ctx.app.EventStore.Subscribe(collectFailures(&renderFailures))
+		err = ctx.app.Execute(&dux.FormatFiles{Files: files.unformatted})
+		stopCollectingFailures()
 	}
 	done()
-
 	if cmd.DryRun {
-		return cmd, nil
+		if err == nil && len(renderFailures) > 0 {
+			err = &dux.RenderFailedError{Blueprint: cmd.BlueprintName, Errors: renderFailures}
+		}
+		return cmd, err
+	}
+	files.removeFailed(ctx.app.FileSystem)
+	if err == nil && len(renderFailures) > 0 && !cmd.KeepGoing {
+		err = &dux.RenderFailedError{Blueprint: cmd.BlueprintName, Errors: renderFailures}
+	}
+	if err != nil {
+		files.removeSources(ctx.app.FileSystem)
+		return cmd, err
 	}
-	return cmd, ctx.app.Execute(&dux.Install{
+	sources, destinations := files.sources, files.destinations
+
+	failures := []error{}
+	stopCollectingFailures := ctx.app.EventStore.Subscribe(collectFailures(&failures))
+	defer stopCollectingFailures()
+	stopCollectingFiles := ctx.app.EventStore.Subscribe(files.collect)
+	err = ctx.app.Execute(&dux.Install{
 		Sources:      sources,
 		Destinations: destinations,
+		Force:        cmd.Force,
 	})
+	stopCollectingFiles()
+	files.removeFailed(ctx.app.FileSystem)
+	if err != nil {
+		return cmd, err
+	}
+	if len(failures) > 0 {
+		return cmd, &InstallError{Errors: failures}
+	}
+	if len(renderFailures) > 0 {
+		return cmd, &dux.RenderFailedError{Blueprint: cmd.BlueprintName, Errors: renderFailures}
+	}
+
+	if cmd.NoHooks || len(blueprint.PostInstall) == 0 {
+		return cmd, nil
+	}
+	if err := ctx.app.Execute(&dux.RunHooks{
+		BlueprintName: cmd.BlueprintName,
+		Data:          data,
+		Files:         destinations,
+	}); err != nil {
+		return cmd, err
+	}
+	if len(failures) > 0 {
+		return cmd, &HookError{Errors: failures}
+	}
+	return cmd, nil
 }
 
-// parseData parses a series of VAR=VALUE assignments in args as a map
-// of string to string.
-func (cmd *CommandNew) parseData(args []string) map[string]interface{} {
-	result := map[string]interface{}{}
-	for _, arg := range args {
-		parts := strings.Split(arg, "=")
-		name, values := parts[0], parts[1:]
-		value := strings.Join(values, "=")
-		result[name] = value
+// ShowBlueprintUsage writes a help message explaining how to pass the
+// arguments of blueprint to out.
+func (cmd *CommandNew) ShowBlueprintUsage(out io.Writer, blueprint *dux.Blueprint) {
+	path := cmd.Spec().Name
+	if parent := cmd.CommandPath(); parent != "" {
+		path = parent + " " + path
 	}
+	blueprintSpec(blueprint).WriteUsage(out, path+" "+blueprint.Name, cmd.blueprintFlags(blueprint, newArgumentValues()))
+	fmt.Fprintf(out, "Arguments can also be passed as NAME=VALUE.\n")
+}
 
-	return result
+// blueprintFlags returns the options of this command together with
+// a flag for every argument of blueprint, storing values in values.
+func (cmd *CommandNew) blueprintFlags(blueprint *dux.Blueprint, values *argumentValues) *flag.FlagSet {
+	flags := flag.NewFlagSet(blueprint.Name, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.Usage = func() {}
+	cmd.defineFlags(flags)
+	defineArgumentFlags(flags, blueprint, values)
+	return flags
 }
 
-// collectRenderedFiles listens to events emitted by RenderBlueprint to build a list of files to install.
-func (cmd *CommandNew) collectRenderedFiles(sources *[]string, destinations *[]string) func(*dux.Event) {
-	return func(e *dux.Event) {
-		if e.Name != "template-rendered" {
-			return
-		}
+// argumentFlags implements hasArgumentFlags by adding the flags of the
+// blueprint named by the first argument to the options of this command.
+func (cmd *CommandNew) argumentFlags(ctx *CLI, args []string) *flag.FlagSet {
+	if len(args) == 0 {
+		return cmd.Options()
+	}
+	blueprint := new(dux.Blueprint)
+	if err := ctx.app.Store.Get(args[0], blueprint); err != nil {
+		return cmd.Options()
+	}
+	return cmd.blueprintFlags(blueprint, newArgumentValues())
+}
 
+// renderedFiles tracks the files staged by RenderBlueprint.
+type renderedFiles struct {
+	sources      []string // The staged files to install
+	destinations []string // Where to install each of sources
+	unformatted  []string // The staged files rendered from a template, rather than copied verbatim
+	failed       []string // The staged files that must not or could not be installed
+}
+
+// collect listens to events emitted by RenderBlueprint and
+// FormatFiles to build the list of files to install.  Files that
+// cannot be formatted are moved to the list of failed files, as are
+// files that Install could not move into place.
+func (files *renderedFiles) collect(e *dux.Event) {
+	switch e.Name {
+	case "template-rendered":
+		source := e.Payload["filename"].(string)
+		files.sources = append(files.sources, source)
+		files.destinations = append(files.destinations, filepath.Clean(strings.Replace(source, ".dux", ".", 1)))
+		if copied, _ := e.Payload["copied"].(bool); !copied {
+			files.unformatted = append(files.unformatted, source)
+		}
+	case "file-format-failed":
 		source := e.Payload["filename"].(string)
-		*sources = append(*sources, source)
-		destination := strings.Replace(source, ".dux", ".", 1)
-		*destinations = append(*destinations, destination)
+		for i := range files.sources {
+			if files.sources[i] == source {
+				files.sources = append(files.sources[:i], files.sources[i+1:]...)
+				files.destinations = append(files.destinations[:i], files.destinations[i+1:]...)
+				files.failed = append(files.failed, source)
+				break
+			}
+		}
+	case "file-conflict", "file-rename-failed":
+		files.failed = append(files.failed, e.Payload["from"].(string))
+	}
+}
+
+// removeFailed removes the staged files that must not be installed
+// or could not be installed.
+func (files *renderedFiles) removeFailed(fs dux.FileSystem) {
+	for _, file := range files.failed {
+		fs.Remove(file)
+	}
+	files.failed = nil
+}
+
+// removeSources removes the staged files when nothing is installed.
+func (files *renderedFiles) removeSources(fs dux.FileSystem) {
+	for _, file := range files.sources {
+		fs.Remove(file)
+	}
+	files.sources, files.destinations = nil, nil
+}
+
+// collectFailures returns an event subscriber that records the errors of
+// failed events in failures.
+func collectFailures(failures *[]error) func(*dux.Event) {
+	return func(e *dux.Event) {
+		if e.Error != nil {
+			*failures = append(*failures, e.Error)
+		}
 	}
 }
 
 // Options implements Command
 func (cmd *CommandNew) Options() *flag.FlagSet {
 	flags := flag.NewFlagSet("new", flag.ContinueOnError)
-	flags.StringVar(&cmd.Destination, "destination", "", "Directory into which to render the blueprint")
-	flags.BoolVar(&cmd.DryRun, "dry-run", false, "Do not move generated files into current directory")
+	cmd.Destination, cmd.DryRun, cmd.Force, cmd.NoHooks, cmd.NoFormat, cmd.KeepGoing = "", false, false, false, false, false
+	cmd.defineFlags(flags)
 	return flags
 }
 
-// Description implements HasDescription
-func (cmd *CommandNew) Description() string { return `Create new files from blueprint` }
+// defineFlags adds the options of this command to flags, keeping
+// their current values.  This allows passing them after the name of
+// the blueprint as well.
+func (cmd *CommandNew) defineFlags(flags *flag.FlagSet) {
+	flags.StringVar(&cmd.Destination, "destination", cmd.Destination, "Directory into which to render the blueprint")
+	flags.BoolVar(&cmd.DryRun, "dry-run", cmd.DryRun, "Do not move generated files into current directory")
+	flags.BoolVar(&cmd.Force, "force", cmd.Force, "Overwrite existing files with different contents")
+	flags.BoolVar(&cmd.NoHooks, "no-hooks", cmd.NoHooks, "Do not run the blueprint's post install hooks")
+	flags.BoolVar(&cmd.NoFormat, "no-format", cmd.NoFormat, "Do not format generated files")
+	flags.BoolVar(&cmd.KeepGoing, "keep-going", cmd.KeepGoing, "Install the files that could be generated even if others failed")
+}
+
+// Spec implements HasSpec
+func (cmd *CommandNew) Spec() *Spec {
+	return &Spec{
+		Name:    "new",
+		Summary: "Create new files from blueprint",
+		Description: `Render BLUEPRINT with the given variables and move the generated files into
+the current directory.  Existing files are only replaced when using --force.
+
+Generated files are formatted before they are installed if dux knows how to
+format them, e.g. Go files are formatted like gofmt does.  If a file cannot be
+formatted, nothing is installed.  Use --no-format to install files as rendered.
+
+If any file cannot be generated, all errors are reported and nothing is
+installed.  Use --keep-going to install the files that could be generated
+anyway.  Hooks are only run if all files have been generated.
+
+Values for the arguments declared by BLUEPRINT are passed as flags like
+--NAME VALUE, as assignments like NAME=VALUE, or, for required arguments, as
+plain values in the order in which they have been declared.  Underscores in
+the names of flags can be written as dashes, e.g. --with-tests for with_tests.
 
-// ShowUsage implements HasUsage
-func (cmd *CommandNew) ShowUsage(out io.Writer) {
-	fmt.Fprintf(out, "Usage: %s new [--dry-run] BLUEPRINT\n\n", cmd.CommandPath())
-	fmt.Fprintf(out, "Options:\n")
-	fmt.Fprintf(out, " --dry-run=false   Do not move generated files into current directory\n")
-	fmt.Fprintf(out, "\n")
+Dotted names like db.table=users create nested values and giving the same
+name more than once creates a list.  NAME:=JSON assigns a JSON value and
+@FILE reads values from a file containing a JSON object.  Run
+
+  dux new BLUEPRINT --help
+
+to see the arguments of BLUEPRINT.  Values which are not given on the command
+line are prompted for when running in a terminal, unless --no-input is given.
+
+After installing the files, the commands declared by BLUEPRINT using
+'dux blueprint hook' are run in the project root, unless --no-hooks is given.`,
+		Arguments: []*ArgumentSpec{
+			{Name: "BLUEPRINT", Description: "Name of the blueprint", Complete: CompleteBlueprints},
+			{Name: "ARGUMENT", Description: "Values for the blueprint's arguments", Optional: true, Repeated: true, Complete: CompleteBlueprintArguments},
+		},
+	}
 }
@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"flag"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/dhamidi/dux"
+)
+
+// Completer returns candidates for a positional argument, given the
+// positional arguments preceding it.
+type Completer func(ctx *CLI, args []string) []string
+
+// Complete returns the candidates for the last entry in words, which
+// is the partial word being completed, when invoking cmd with words.
+func Complete(ctx *CLI, cmd Command, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current, preceding := words[len(words)-1], words[:len(words)-1]
+
+	if dispatcher, ok := cmd.(*DispatchCommand); ok {
+		return completeDispatch(ctx, dispatcher, current, preceding)
+	}
+
+	options := cmd.Options()
+	if flags, ok := cmd.(hasArgumentFlags); ok {
+		options = flags.argumentFlags(ctx, positionalArgs(options, preceding))
+	}
+	if strings.HasPrefix(current, "-") {
+		return withPrefix(flagNames(options), current)
+	}
+
+	spec, ok := cmd.(HasSpec)
+	if !ok {
+		return nil
+	}
+	args := positionalArgs(options, preceding)
+	arguments := spec.Spec().Arguments
+	if len(arguments) == 0 {
+		return nil
+	}
+	argument := arguments[len(arguments)-1]
+	if len(args) < len(arguments) {
+		argument = arguments[len(args)]
+	} else if !argument.Repeated {
+		return nil
+	}
+	if argument.Complete == nil {
+		return nil
+	}
+	return withPrefix(argument.Complete(ctx, args), current)
+}
+
+// hasArgumentFlags is implemented by commands that accept more flags
+// than their Options, depending on their positional arguments.
+type hasArgumentFlags interface {
+	// argumentFlags returns all flags accepted by the command when
+	// invoked with args.
+	argumentFlags(ctx *CLI, args []string) *flag.FlagSet
+}
+
+// completeDispatch completes global options, subcommand names or the
+// arguments of the selected subcommand.
+func completeDispatch(ctx *CLI, cmd *DispatchCommand, current string, preceding []string) []string {
+	if len(preceding) > 0 && preceding[0] == cmd.name {
+		preceding = preceding[1:]
+	}
+	if cmd.options != nil {
+		if strings.HasPrefix(current, "-") && len(positionalArgs(cmd.options, preceding)) == 0 {
+			return withPrefix(flagNames(cmd.options), current)
+		}
+		preceding = positionalArgs(cmd.options, preceding)
+	}
+
+	if len(preceding) == 0 {
+		return withPrefix(cmd.Subcommands(), current)
+	}
+	if preceding[0] == "help" {
+		if _, found := cmd.subcommands["help"]; !found {
+			return completeHelp(cmd, current, preceding[1:])
+		}
+	}
+	subcommand, found := cmd.subcommands[preceding[0]]
+	if !found {
+		return nil
+	}
+	return Complete(ctx, subcommand, append(append([]string{}, preceding[1:]...), current))
+}
+
+// completeHelp completes the path of a command after "help".
+func completeHelp(cmd *DispatchCommand, current string, path []string) []string {
+	for _, name := range path {
+		subcommand, found := cmd.subcommands[name]
+		if !found {
+			return nil
+		}
+		dispatcher, ok := subcommand.(*DispatchCommand)
+		if !ok {
+			return nil
+		}
+		cmd = dispatcher
+	}
+	return withPrefix(cmd.Subcommands(), current)
+}
+
+// positionalArgs removes all flags and their values from words.
+func positionalArgs(options *flag.FlagSet, words []string) []string {
+	if options == nil {
+		options = flag.NewFlagSet("", flag.ContinueOnError)
+	}
+	options.SetOutput(ioutil.Discard)
+	options.Usage = func() {}
+	options.Parse(words)
+	return options.Args()
+}
+
+// flagNames returns the names of all flags in options, prefixed with
+// "--".  Aliases of other flags are omitted.
+func flagNames(options *flag.FlagSet) []string {
+	names := []string{}
+	if options == nil {
+		return names
+	}
+	options.VisitAll(func(f *flag.Flag) {
+		if _, alias := f.Value.(*aliasFlag); alias {
+			return
+		}
+		names = append(names, "--"+f.Name)
+	})
+	return names
+}
+
+// withPrefix returns all candidates starting with prefix, sorted.
+func withPrefix(candidates []string, prefix string) []string {
+	result := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			result = append(result, candidate)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CompleteBlueprints is a Completer returning the names of all stored
+// blueprints.
+func CompleteBlueprints(ctx *CLI, args []string) []string {
+	names, err := ctx.app.Store.List("*")
+	if err != nil {
+		return nil
+	}
+	return names
+}
+
+// CompleteTemplates is a Completer returning the names of the
+// templates of the blueprint passed as the first argument.
+func CompleteTemplates(ctx *CLI, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	names := []string{}
+	done := ctx.app.EventStore.Subscribe(func(e *dux.Event) {
+		if e.Name == "blueprint-template-found" {
+			names = append(names, e.Payload["name"].(string))
+		}
+	})
+	defer done()
+	if err := ctx.app.Execute(&dux.ListTemplates{BlueprintName: args[0]}); err != nil {
+		return nil
+	}
+	return names
+}
+
+// CompleteBlueprintArguments is a Completer returning "NAME=" for every
+// argument declared by the blueprint passed as the first argument,
+// omitting arguments that have been assigned already.
+func CompleteBlueprintArguments(ctx *CLI, args []string) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	blueprint := new(dux.Blueprint)
+	if err := ctx.app.Store.Get(args[0], blueprint); err != nil {
+		return nil
+	}
+	assigned := map[string]bool{}
+	for _, arg := range args[1:] {
+		assigned[strings.SplitN(arg, "=", 2)[0]] = true
+	}
+	names := []string{}
+	for _, argument := range blueprint.Arguments {
+		if !assigned[argument.Name] {
+			names = append(names, argument.Name+"=")
+		}
+	}
+	return names
+}
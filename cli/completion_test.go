@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestComplete(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+	h.FailOnExecuteError(t, app)(&dux.DefineBlueprintArgument{
+		BlueprintName: "a",
+		Argument:      &dux.Argument{Name: "name"},
+	})
+	h.FailOnExecuteError(t, app)(&dux.DefineBlueprintArgument{
+		BlueprintName: "a",
+		Argument:      &dux.Argument{Name: "with_tests", Type: dux.ArgumentTypeBool},
+	})
+	ctx := cli.NewCLI(app)
+	dispatcher := newDispatcher().
+		Add("__complete", cli.NewCommandComplete()).
+		Add("file", cli.NewCommandBlueprintFile())
+
+	testcases := []struct {
+		words    []string
+		expected []string
+	}{
+		{[]string{""}, []string{"blueprint", "file", "list", "new"}},
+		{[]string{"bl"}, []string{"blueprint"}},
+		{[]string{"blueprint", ""}, []string{"lint"}},
+		{[]string{"help", "blueprint", ""}, []string{"lint"}},
+		{[]string{"new", ""}, []string{"a"}},
+		{[]string{"new", "--dry-run", "a", ""}, []string{"name=", "with_tests="}},
+		{[]string{"new", "a", "name=x", "with_tests=true", ""}, []string{}},
+		{[]string{"new", "--f"}, []string{"--force"}},
+		{[]string{"new", "--w"}, []string{}},
+		{[]string{"new", "a", "--w"}, []string{"--with-tests"}},
+		{[]string{"new", "--dry-run", "a", "--n"}, []string{"--name", "--no-format", "--no-hooks"}},
+		{[]string{"file", "a", "out.txt", "g"}, []string{"greeting.txt"}},
+	}
+
+	for _, testcase := range testcases {
+		actual := cli.Complete(ctx, dispatcher, testcase.words)
+		if actual == nil {
+			actual = []string{}
+		}
+		if !reflect.DeepEqual(actual, testcase.expected) {
+			t.Errorf("Complete(%q): expected %q, got %q", testcase.words, testcase.expected, actual)
+		}
+	}
+}
+
+func TestCommandComplete_prints_one_candidate_per_line(t *testing.T) {
+	app := h.NewApp()
+	dispatcher := newDispatcher().Add("__complete", cli.NewCommandComplete())
+
+	result := h.RunCLI(app, dispatcher, "", "dux", "__complete", "--", "blueprint", "li")
+
+	if result.Stdout != "lint\n" {
+		t.Fatalf("Expected completion of lint, got %q: %s", result.Stdout, result.Stderr)
+	}
+}
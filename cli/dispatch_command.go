@@ -4,6 +4,9 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
 )
 
 // DispatchCommand implements Command by consuming one argument and
@@ -13,6 +16,7 @@ type DispatchCommand struct {
 	name        string
 	description string
 	subcommands map[string]Command
+	options     *flag.FlagSet
 }
 
 // NewDispatchCommand creates a new dispatcher with the given name.
@@ -44,29 +48,71 @@ func (cmd *DispatchCommand) Describe(desc string) *DispatchCommand {
 	return cmd
 }
 
+// WithOptions makes the dispatcher accept the flags defined in
+// options before the name of the subcommand.
+func (cmd *DispatchCommand) WithOptions(options *flag.FlagSet) *DispatchCommand {
+	cmd.options = options
+	return cmd
+}
+
 // Exec implements Command by consuming the first entry in args and
 // dispatching to a command with a matching name.
 func (cmd *DispatchCommand) Exec(ctx *CLI, args []string) (Command, error) {
 	if len(args) == 0 {
-		return cmd, fmt.Errorf("No subcommand provided")
+		return cmd, usageErrorf("No subcommand provided")
 	}
 
 	if args[0] == cmd.name {
 		args = args[1:]
 	}
 
+	if cmd.options != nil {
+		cmd.options.SetOutput(ioutil.Discard)
+		cmd.options.Usage = func() {}
+		if err := cmd.options.Parse(args); err == flag.ErrHelp {
+			cmd.ShowUsage(ctx.out)
+			return cmd, nil
+		} else if err != nil {
+			return cmd, &UsageError{Err: err}
+		}
+		args = cmd.options.Args()
+	}
+
 	if len(args) == 0 {
-		return cmd, fmt.Errorf("No subcommand provided")
+		return cmd, usageErrorf("No subcommand provided")
 	}
 
 	subcommand, found := cmd.subcommands[args[0]]
+	if !found && args[0] == "help" {
+		return cmd.help(ctx, args[1:])
+	}
 	if !found {
-		return cmd, fmt.Errorf("Unknown command: %q", args[0])
+		return cmd, usageErrorf("Unknown command: %q", args[0])
 	}
 
 	return ctx.Execute(subcommand, args[1:])
 }
 
+// help shows the usage of the subcommand found by following path
+// through nested dispatchers.
+func (cmd *DispatchCommand) help(ctx *CLI, path []string) (Command, error) {
+	var target Command = cmd
+	for _, name := range path {
+		dispatcher, ok := target.(*DispatchCommand)
+		if !ok {
+			return target, usageErrorf("Unknown command: %q", name)
+		}
+		subcommand, found := dispatcher.subcommands[name]
+		if !found {
+			return dispatcher, usageErrorf("Unknown command: %q", name)
+		}
+		target = subcommand
+	}
+
+	ShowUsage(ctx.out, target)
+	return cmd, nil
+}
+
 // ShowUsage implements HasUsage by listing all subcommands
 func (cmd *DispatchCommand) ShowUsage(out io.Writer) {
 	if len(cmd.subcommands) == 0 {
@@ -79,22 +125,29 @@ func (cmd *DispatchCommand) ShowUsage(out io.Writer) {
 		fmt.Fprintf(out, "%s\n\n", desc)
 	}
 
+	if cmd.options != nil && hasFlags(cmd.options) {
+		fmt.Fprintf(out, "Options:\n%s\n", formatFlags(cmd.options))
+	}
+
 	fmt.Fprintf(out, "Available commands:\n")
+	names := cmd.Subcommands()
 	longestSubcommandName := ""
-	for name := range cmd.subcommands {
+	for _, name := range names {
 		if len(name) > len(longestSubcommandName) {
 			longestSubcommandName = name
 		}
 	}
 
 	subcommandFormat := fmt.Sprintf("  %%-%ds", len(longestSubcommandName))
-	for name, command := range cmd.subcommands {
+	for _, name := range names {
 		fmt.Fprintf(out, subcommandFormat, name)
-		if description, ok := command.(HasDescription); ok {
-			fmt.Fprintf(out, "  %s", description.Description())
+		if description := describe(cmd.subcommands[name]); description != "" {
+			fmt.Fprintf(out, "  %s", description)
 		}
 		fmt.Fprintf(out, "\n")
 	}
+
+	fmt.Fprintf(out, "\nRun '%s help COMMAND' for more information on a command.\n", cmd.CommandPath())
 }
 
 // Options implements Command by returning the options for the given subcommand
@@ -120,6 +173,26 @@ func (cmd *DispatchCommand) Options() *flag.FlagSet { return nil }
 // 	return subcommand.Options(args[1:])
 // }
 
+// Subcommands returns the names of all visible subcommands in sorted
+// order.  Subcommands whose name starts with "__" are hidden.
+func (cmd *DispatchCommand) Subcommands() []string {
+	names := make([]string, 0, len(cmd.subcommands))
+	for name := range cmd.subcommands {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Subcommand returns the subcommand registered under name.
+func (cmd *DispatchCommand) Subcommand(name string) (Command, bool) {
+	subcommand, found := cmd.subcommands[name]
+	return subcommand, found
+}
+
 // Add defines a new subcommand
 func (cmd *DispatchCommand) Add(name string, command Command) *DispatchCommand {
 	if child, isChild := command.(interface {
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dhamidi/dux"
+)
+
+// Exit codes returned by the dux executable.
+const (
+	ExitOK            = 0 // the command succeeded
+	ExitFailure       = 1 // the command failed for an unspecified reason
+	ExitUsage         = 2 // the command was invoked incorrectly
+	ExitInvalid       = 3 // the input to the command did not pass validation
+	ExitRenderFailed  = 4 // one or more files could not be rendered
+	ExitInstallFailed = 5 // one or more files could not be installed
+	ExitHookFailed    = 6 // the files have been installed, but a post install hook failed
+)
+
+// UsageError indicates that a command has been invoked with missing
+// or malformed arguments.
+type UsageError struct {
+	Err error
+}
+
+// Error implements error
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// usageErrorf returns a UsageError with a message formatted like fmt.Errorf.
+func usageErrorf(format string, args ...interface{}) error {
+	return &UsageError{Err: fmt.Errorf(format, args...)}
+}
+
+// ValidationError indicates that the input to a command, e.g. a
+// blueprint, did not pass validation.
+type ValidationError struct {
+	Err error
+}
+
+// Error implements error
+func (e *ValidationError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// validationErrorf returns a ValidationError with a message formatted like fmt.Errorf.
+func validationErrorf(format string, args ...interface{}) error {
+	return &ValidationError{Err: fmt.Errorf(format, args...)}
+}
+
+// InstallError collects the errors that occurred while moving
+// rendered files into place, e.g. because of conflicting files.
+type InstallError struct {
+	Errors []error
+}
+
+// Error implements error
+func (e *InstallError) Error() string {
+	return fmt.Sprintf("Failed to install %d file(s):\n%s", len(e.Errors), dux.IndentErrors(e.Errors))
+}
+
+// HookError collects the errors of post install hooks that failed
+// after the generated files have been installed.
+type HookError struct {
+	Errors []error
+}
+
+// Error implements error
+func (e *HookError) Error() string {
+	return fmt.Sprintf("Files have been installed, but %d hook(s) failed:\n%s", len(e.Errors), dux.IndentErrors(e.Errors))
+}
+
+// ExitCode returns the exit code the dux executable uses for err.
+func ExitCode(err error) int {
+	var (
+		usageErr      *UsageError
+		validationErr *ValidationError
+		renderErr     *dux.RenderFailedError
+		installErr    *InstallError
+		hookErr       *HookError
+	)
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usageErr):
+		return ExitUsage
+	case errors.As(err, &validationErr):
+		return ExitInvalid
+	case errors.As(err, &renderErr):
+		return ExitRenderFailed
+	case errors.As(err, &installErr):
+		return ExitInstallFailed
+	case errors.As(err, &hookErr):
+		return ExitHookFailed
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dhamidi/dux"
+)
+
+// Event output formats supported by EventRenderer.
+const (
+	EventFormatText = "text"
+	EventFormatJSON = "json"
+)
+
+// statusColors maps status words to ANSI color codes.
+var statusColors = map[string]string{
+	"create":    "32", // green
+	"force":     "33", // yellow
+	"identical": "34", // blue
+	"skip":      "33", // yellow
+	"conflict":  "31", // red
+	"run":       "35", // magenta
+	"error":     "31", // red
+}
+
+// detailEvents are only shown in verbose mode.
+var detailEvents = map[string]bool{
+	"template-rendered":        true,
+	"blueprint-template-found": true,
+	"hook-finished":            true,
+	"file-formatted":           true,
+}
+
+// silentEvents are never shown, because the commands emitting them
+// present them to the user themselves.
+var silentEvents = map[string]bool{
+	"blueprint-exported":         true, // the archive is written to stdout
+	"blueprint-template-changed": true, // shown by blueprint update
+	"blueprint-lint-problem":     true, // shown by blueprint lint
+	"blueprint-fixture-passed":   true, // shown by blueprint test
+	"blueprint-fixture-failed":   true,
+	"blueprint-fixture-updated":  true,
+}
+
+// EventRenderer presents events to the user, either as status lines
+// like "create path/to/file" or as one JSON object per event.
+type EventRenderer struct {
+	out     io.Writer
+	err     io.Writer
+	Format  string
+	Quiet   bool
+	Verbose bool
+	Color   bool
+	counts  map[string]int
+}
+
+// NewEventRenderer returns a renderer writing status lines to out and
+// errors to err, using colors if out is a terminal.
+func NewEventRenderer(out, err io.Writer) *EventRenderer {
+	return &EventRenderer{
+		out:    out,
+		err:    err,
+		Format: EventFormatText,
+		Color:  isTerminal(out) && os.Getenv("NO_COLOR") == "",
+		counts: map[string]int{},
+	}
+}
+
+// DefineFlags adds the --quiet, --verbose and --format flags controlling
+// this renderer to flags.
+func (r *EventRenderer) DefineFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&r.Quiet, "quiet", false, "Only show errors")
+	flags.BoolVar(&r.Verbose, "verbose", false, "Show all events, including intermediate steps")
+	flags.StringVar(&r.Format, "format", EventFormatText, "Show events as text or json")
+}
+
+// Render presents a single event to the user.
+func (r *EventRenderer) Render(e *dux.Event) {
+	if silentEvents[e.Name] {
+		return
+	}
+	status, path := eventStatus(e)
+	if status != "" {
+		r.counts[status]++
+	}
+
+	if r.Format == EventFormatJSON {
+		r.renderJSON(e)
+		return
+	}
+
+	if e.Error != nil && status == "" {
+		r.statusLine(r.err, "error", e.Error.Error())
+		r.snippet(e)
+		return
+	}
+	if r.Quiet && status != "conflict" {
+		return
+	}
+	if e.Name == "hook-output" {
+		line, _ := e.Payload["line"].(string)
+		r.statusLine(r.out, "", line)
+		return
+	}
+	if status != "" {
+		r.statusLine(r.out, status, path)
+		return
+	}
+	if detailEvents[e.Name] && !r.Verbose {
+		return
+	}
+	r.statusLine(r.out, e.Name, formatPayload(e.Payload))
+}
+
+// Summary prints the number of files per status, if any files have
+// been processed.
+func (r *EventRenderer) Summary() {
+	if r.Format == EventFormatJSON || r.Quiet || len(r.counts) == 0 {
+		return
+	}
+	statuses := make([]string, 0, len(r.counts))
+	for status := range r.counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = fmt.Sprintf("%d %s", r.counts[status], status)
+	}
+	fmt.Fprintf(r.out, "\n%s\n", strings.Join(parts, ", "))
+}
+
+// statusLine writes status, right-aligned, followed by message.
+func (r *EventRenderer) statusLine(out io.Writer, status, message string) {
+	label := fmt.Sprintf("%12s", status)
+	if color, found := statusColors[status]; found && r.Color {
+		label = fmt.Sprintf("\x1b[1;%sm%s\x1b[0m", color, label)
+	}
+	fmt.Fprintf(out, "%s  %s\n", label, message)
+}
+
+// snippet writes the lines of source code around the location of the
+// error of e, if e carries that information.
+func (r *EventRenderer) snippet(e *dux.Event) {
+	snippet := ""
+	var templateErr *dux.TemplateError
+	if errors.As(e.Error, &templateErr) {
+		snippet = templateErr.Snippet
+	} else if e.Name == "file-format-failed" {
+		source, _ := e.Payload["source"].(string)
+		line, _ := e.Payload["line"].(int)
+		column, _ := e.Payload["column"].(int)
+		snippet = dux.SourceSnippet(source, line, column, 2)
+	}
+	for _, line := range strings.SplitAfter(snippet, "\n") {
+		if line != "" {
+			fmt.Fprintf(r.err, "%12s  %s", "", line)
+		}
+	}
+}
+
+// renderJSON writes e as a single line of JSON.
+func (r *EventRenderer) renderJSON(e *dux.Event) {
+	object := map[string]interface{}{
+		"id":      e.ID,
+		"name":    e.Name,
+		"payload": e.Payload,
+	}
+	if !e.Time.IsZero() {
+		object["time"] = e.Time.Format(time.RFC3339Nano)
+	}
+	if e.Error != nil {
+		object["error"] = e.Error.Error()
+	}
+	line, err := json.Marshal(object)
+	if err != nil {
+		object["payload"] = formatPayload(e.Payload)
+		line, _ = json.Marshal(object)
+	}
+	fmt.Fprintf(r.out, "%s\n", line)
+}
+
+// eventStatus returns the status word and path describing the effect of
+// e on a file, or empty strings if e does not concern a file.
+func eventStatus(e *dux.Event) (status, path string) {
+	path, _ = e.Payload["to"].(string)
+	switch {
+	case e.Name == "file-renamed" && e.Payload["overwritten"] == true:
+		return "force", path
+	case e.Name == "file-renamed":
+		return "create", path
+	case e.Name == "file-identical":
+		return "identical", path
+	case e.Name == "file-conflict":
+		return "conflict", path
+	case strings.HasPrefix(e.Name, "file-skipped"):
+		path, _ = e.Payload["filename"].(string)
+		return "skip", path
+	case e.Name == "hook-started":
+		path, _ = e.Payload["command"].(string)
+		return "run", path
+	}
+	return "", ""
+}
+
+// formatPayload renders payload as space separated key=value pairs,
+// sorted by key.
+func formatPayload(payload dux.EventPayload) string {
+	keys := make([]string, 0, len(payload))
+	for key := range payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = fmt.Sprintf("%s=%v", key, payload[key])
+	}
+	return strings.Join(pairs, " ")
+}
+
+// isTerminal reports whether stream, a reader or writer, is connected
+// to a terminal.
+func isTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,105 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+)
+
+func TestEventRenderer_Render_shows_status_lines_for_files(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	renderer := cli.NewEventRenderer(out, errOut)
+
+	renderer.Render(&dux.Event{Name: "file-renamed", Payload: dux.EventPayload{"to": "a.txt"}})
+	renderer.Render(&dux.Event{Name: "file-identical", Payload: dux.EventPayload{"to": "b.txt"}})
+	renderer.Render(&dux.Event{Name: "file-conflict", Payload: dux.EventPayload{"to": "c.txt"}, Error: errors.New("conflict")})
+	renderer.Render(&dux.Event{Name: "template-rendered", Payload: dux.EventPayload{"filename": "a.txt"}})
+	renderer.Summary()
+
+	expected := "" +
+		"      create  a.txt\n" +
+		"   identical  b.txt\n" +
+		"    conflict  c.txt\n" +
+		"\n" +
+		"1 conflict, 1 create, 1 identical\n"
+	if out.String() != expected {
+		t.Fatalf("Expected output:\n%s\nActual output:\n%s", expected, out)
+	}
+}
+
+func TestEventRenderer_Render_shows_intermediate_steps_when_verbose(t *testing.T) {
+	out := new(bytes.Buffer)
+	renderer := cli.NewEventRenderer(out, out)
+	renderer.Verbose = true
+
+	renderer.Render(&dux.Event{Name: "template-rendered", Payload: dux.EventPayload{"filename": "a.txt", "template": "a"}})
+
+	if !strings.Contains(out.String(), "template-rendered  filename=a.txt template=a") {
+		t.Fatalf("Expected event in output, got %q", out)
+	}
+}
+
+func TestEventRenderer_Render_only_shows_errors_when_quiet(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	renderer := cli.NewEventRenderer(out, errOut)
+	renderer.Quiet = true
+
+	renderer.Render(&dux.Event{Name: "file-renamed", Payload: dux.EventPayload{"to": "a.txt"}})
+	renderer.Render(&dux.Event{Name: "render-template-failed", Error: errors.New("boom")})
+	renderer.Summary()
+
+	if out.String() != "" {
+		t.Fatalf("Expected no output, got %q", out)
+	}
+	if !strings.Contains(errOut.String(), "error  boom") {
+		t.Fatalf("Expected error in output, got %q", errOut)
+	}
+}
+
+func TestEventRenderer_Render_emits_one_json_object_per_event(t *testing.T) {
+	out := new(bytes.Buffer)
+	renderer := cli.NewEventRenderer(out, out)
+	renderer.Format = cli.EventFormatJSON
+
+	renderer.Render(&dux.Event{ID: "1", Name: "file-renamed", Payload: dux.EventPayload{"to": "a.txt"}})
+	renderer.Render(&dux.Event{ID: "2", Name: "render-template-failed", Error: errors.New("boom")})
+	renderer.Summary()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", out)
+	}
+	event := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event["name"] != "render-template-failed" || event["error"] != "boom" {
+		t.Fatalf("Unexpected event: %v", event)
+	}
+}
+
+func TestEventRenderer_Render_shows_snippet_of_template_errors(t *testing.T) {
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	renderer := cli.NewEventRenderer(out, errOut)
+
+	renderer.Render(&dux.Event{Name: "render-template-failed", Error: &dux.TemplateError{
+		Template: "x.tmpl",
+		Line:     1,
+		Column:   4,
+		Message:  "boom",
+		Snippet:  dux.SourceSnippet("ab {{ .c }}", 1, 4, 2),
+	}})
+
+	expected := "" +
+		"       error  x.tmpl:1:4: boom\n" +
+		"                 1 | ab {{ .c }}\n" +
+		"                   |    ^\n"
+	if errOut.String() != expected {
+		t.Fatalf("Expected output:\n%s\nActual output:\n%s", expected, errOut)
+	}
+}
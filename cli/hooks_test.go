@@ -0,0 +1,41 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestCLI_Run_runs_hooks_after_installing_files(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!", h.DefineBlueprintHook("a", "echo formatting {{.files}}"))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	for _, expected := range []string{"run  echo formatting world.txt", "formatting world.txt"} {
+		if !strings.Contains(result.Stdout, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result.Stdout)
+		}
+	}
+}
+
+func TestCLI_Run_skips_hooks_with_no_hooks(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!", h.DefineBlueprintHook("a", "echo formatting {{.files}}"))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "--no-hooks", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertEventCount(t, app.EventStore, "hook-started", 0)
+}
+
+func TestCLI_Run_keeps_installed_files_when_hooks_fail(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!", h.DefineBlueprintHook("a", "exit 1"))
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitHookFailed)
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world!")
+}
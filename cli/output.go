@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dhamidi/dux"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats supported by the --output option.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// OutputSchemaVersion is the version of the structures written by
+// read-only commands when using --output json or --output yaml.  It is
+// incremented whenever a field is removed or changes its meaning.
+const OutputSchemaVersion = 1
+
+// Output is implemented by the results of read-only commands.  Results
+// are written as JSON or YAML using their struct tags, or as text for
+// humans using WriteText.
+type Output interface {
+	WriteText(out io.Writer)
+}
+
+// outputFormatFlag is the value of the --output option.  Unknown
+// formats are rejected while parsing the option.
+type outputFormatFlag struct {
+	format *string
+}
+
+// String implements flag.Value
+func (f *outputFormatFlag) String() string {
+	if f.format == nil {
+		return ""
+	}
+	return *f.format
+}
+
+// Set implements flag.Value
+func (f *outputFormatFlag) Set(value string) error {
+	switch value {
+	case OutputText, OutputJSON, OutputYAML:
+		*f.format = value
+		return nil
+	default:
+		return fmt.Errorf("Unknown output format: %q", value)
+	}
+}
+
+// Show writes value to the CLI's output in the format selected with
+// --output.
+func (cli *CLI) Show(value Output) error {
+	switch cli.OutputFormat {
+	case OutputText, "":
+		value.WriteText(cli.out)
+		return nil
+	case OutputJSON:
+		encoder := json.NewEncoder(cli.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(cli.out)
+		defer encoder.Close()
+		return encoder.Encode(value)
+	default:
+		return usageErrorf("Unknown output format: %q", cli.OutputFormat)
+	}
+}
+
+// BlueprintListOutput is the result of the list command.
+type BlueprintListOutput struct {
+	SchemaVersion int                       `json:"schemaVersion" yaml:"schemaVersion"`
+	Blueprints    []*BlueprintSummaryOutput `json:"blueprints" yaml:"blueprints"`
+}
+
+// BlueprintSummaryOutput describes a single blueprint in a list.
+type BlueprintSummaryOutput struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// WriteText implements Output by listing one blueprint per line.
+func (o *BlueprintListOutput) WriteText(out io.Writer) {
+	labelWidth := 20
+	for _, blueprint := range o.Blueprints {
+		if len(blueprint.Name) > labelWidth {
+			labelWidth = len(blueprint.Name)
+		}
+	}
+	entryFormat := fmt.Sprintf("%%-%ds", labelWidth)
+	for _, blueprint := range o.Blueprints {
+		fmt.Fprintf(out, entryFormat, blueprint.Name)
+		if len(blueprint.Description) > 0 {
+			fmt.Fprintf(out, " # %s", blueprint.Description)
+		}
+		fmt.Fprintf(out, "\n")
+	}
+}
+
+// BlueprintOutput is the result of the blueprint show command.
+type BlueprintOutput struct {
+	SchemaVersion int                        `json:"schemaVersion" yaml:"schemaVersion"`
+	Name          string                     `json:"name" yaml:"name"`
+	Description   string                     `json:"description,omitempty" yaml:"description,omitempty"`
+	Origin        *BlueprintOriginOutput     `json:"origin,omitempty" yaml:"origin,omitempty"`
+	Arguments     []*BlueprintArgumentOutput `json:"arguments" yaml:"arguments"`
+	Files         []*BlueprintFileOutput     `json:"files" yaml:"files"`
+	Templates     []string                   `json:"templates" yaml:"templates"`
+	PostInstall   []string                   `json:"postInstall,omitempty" yaml:"postInstall,omitempty"`
+}
+
+// BlueprintOriginOutput describes where a blueprint has been installed from.
+type BlueprintOriginOutput struct {
+	URL    string `json:"url" yaml:"url"`
+	Ref    string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	Commit string `json:"commit" yaml:"commit"`
+}
+
+// BlueprintArgumentOutput describes an argument accepted by a blueprint.
+type BlueprintArgumentOutput struct {
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     string   `json:"default,omitempty" yaml:"default,omitempty"`
+	Required    bool     `json:"required" yaml:"required"`
+	Choices     []string `json:"choices,omitempty" yaml:"choices,omitempty"`
+}
+
+// BlueprintFileOutput describes a file generated by a blueprint.  Mode
+// is an octal string like "0755".
+type BlueprintFileOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Template string `json:"template" yaml:"template"`
+	Mode     string `json:"mode" yaml:"mode"`
+
+	// Action is "copy" or "render" if the file is always
+	// generated that way.  Condition is the template deciding
+	// whether the file is generated and Each the name of the value
+	// over which the file iterates.
+	Action    string `json:"action,omitempty" yaml:"action,omitempty"`
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+	Each      string `json:"each,omitempty" yaml:"each,omitempty"`
+}
+
+// defaultFileModeOutput is the mode of files which are not marked as
+// executable or otherwise special.
+var defaultFileModeOutput = fileModeOutput(dux.DefaultFileMode)
+
+// fileModeOutput formats mode as an octal string.
+func fileModeOutput(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", mode.Perm())
+}
+
+// WriteText implements Output by describing the blueprint in human
+// readable form.
+func (o *BlueprintOutput) WriteText(out io.Writer) {
+	fmt.Fprintf(out, "Name: %s\n", o.Name)
+	if len(o.Description) > 0 {
+		fmt.Fprintf(out, "Description: %s\n", o.Description)
+	}
+	if o.Origin != nil {
+		fmt.Fprintf(out, "Origin: %s@%s\n", o.Origin.URL, o.Origin.Commit)
+	}
+	if len(o.Arguments) > 0 {
+		fmt.Fprintf(out, "Arguments:\n")
+		for _, arg := range o.Arguments {
+			fmt.Fprintf(out, "  - name: %s\n", arg.Name)
+			if arg.Type != "" {
+				fmt.Fprintf(out, "    type: %s\n", arg.Type)
+			}
+			if arg.Description != "" {
+				fmt.Fprintf(out, "    description: %s\n", arg.Description)
+			}
+			if arg.Default != "" {
+				fmt.Fprintf(out, "    default: %s\n", arg.Default)
+			}
+			if arg.Required {
+				fmt.Fprintf(out, "    required: true\n")
+			}
+			if len(arg.Choices) > 0 {
+				fmt.Fprintf(out, "    choices: %s\n", strings.Join(arg.Choices, ", "))
+			}
+		}
+	}
+	if len(o.Files) > 0 {
+		fmt.Fprintf(out, "Files:\n")
+		for _, file := range o.Files {
+			fmt.Fprintf(out, "  - name: %s\n", file.Name)
+			fmt.Fprintf(out, "    template: %s\n", file.Template)
+			if file.Mode != defaultFileModeOutput {
+				fmt.Fprintf(out, "    mode: %s\n", file.Mode)
+			}
+			if file.Action != "" {
+				fmt.Fprintf(out, "    action: %s\n", file.Action)
+			}
+			if file.Condition != "" {
+				fmt.Fprintf(out, "    if: %s\n", file.Condition)
+			}
+			if file.Each != "" {
+				fmt.Fprintf(out, "    each: %s\n", file.Each)
+			}
+		}
+		fmt.Fprintf(out, "\n")
+	}
+	if len(o.Templates) > 0 {
+		fmt.Fprintf(out, "Templates:\n")
+		for _, name := range o.Templates {
+			fmt.Fprintf(out, "  - %s\n", name)
+		}
+	}
+	if len(o.PostInstall) > 0 {
+		fmt.Fprintf(out, "Post install:\n")
+		for _, command := range o.PostInstall {
+			fmt.Fprintf(out, "  - %s\n", command)
+		}
+	}
+}
+
+// LintOutput is the result of the blueprint lint command.
+type LintOutput struct {
+	SchemaVersion int                  `json:"schemaVersion" yaml:"schemaVersion"`
+	Blueprint     string               `json:"blueprint" yaml:"blueprint"`
+	Problems      []*LintProblemOutput `json:"problems" yaml:"problems"`
+}
+
+// LintProblemOutput describes a single problem found in a blueprint.
+// Line is 0 if the line is unknown.
+type LintProblemOutput struct {
+	File    string `json:"file" yaml:"file"`
+	Line    int    `json:"line" yaml:"line"`
+	Kind    string `json:"kind" yaml:"kind"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// WriteText implements Output by listing one problem per line.
+func (o *LintOutput) WriteText(out io.Writer) {
+	for _, problem := range o.Problems {
+		if problem.Line > 0 {
+			fmt.Fprintf(out, "%s:%d: %s [%s]\n", problem.File, problem.Line, problem.Message, problem.Kind)
+		} else {
+			fmt.Fprintf(out, "%s: %s [%s]\n", problem.File, problem.Message, problem.Kind)
+		}
+	}
+}
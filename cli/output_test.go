@@ -0,0 +1,89 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+	"gopkg.in/yaml.v3"
+)
+
+func runWithOutputFormat(t *testing.T, app *dux.Application, cmd cli.Command, format string, args ...string) []byte {
+	t.Helper()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	ctx := cli.NewCLI(app, cli.WithOutput(stdout), cli.WithErrorOutput(stderr))
+	ctx.OutputFormat = format
+	if status := ctx.Run(cmd, args); status != cli.ExitOK {
+		t.Fatalf("Expected exit status %d, got %d: %s", cli.ExitOK, status, stderr)
+	}
+	return stdout.Bytes()
+}
+
+func TestCommandList_writes_json(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+
+	output := new(cli.BlueprintListOutput)
+	if err := json.Unmarshal(runWithOutputFormat(t, app, newDispatcher(), cli.OutputJSON, "dux", "list"), output); err != nil {
+		t.Fatal(err)
+	}
+
+	if output.SchemaVersion != cli.OutputSchemaVersion {
+		t.Fatalf("Expected schema version %d, got %d", cli.OutputSchemaVersion, output.SchemaVersion)
+	}
+	if len(output.Blueprints) != 1 || output.Blueprints[0].Name != "a" || output.Blueprints[0].Description != "A test" {
+		t.Fatalf("Unexpected blueprints: %#v", output.Blueprints)
+	}
+}
+
+func TestCommandBlueprintShow_writes_yaml(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+	dispatcher := cli.NewDispatchCommand("dux").Add("show", cli.NewCommandBlueprintShow())
+	stdout := runWithOutputFormat(t, app, dispatcher, cli.OutputYAML, "dux", "show", "a")
+
+	output := new(cli.BlueprintOutput)
+	if err := yaml.Unmarshal(stdout, output); err != nil {
+		t.Fatalf("Invalid YAML: %s\n%s", err, stdout)
+	}
+	if len(output.Files) != 1 || output.Files[0].Template != "greeting.txt" || output.Files[0].Mode != "0644" {
+		t.Fatalf("Unexpected files: %#v", output.Files)
+	}
+	if len(output.Templates) != 1 || output.Templates[0] != "greeting.txt" {
+		t.Fatalf("Unexpected templates: %#v", output.Templates)
+	}
+}
+
+func TestCommandBlueprintShow_lists_conditions_and_iterations(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+	app.Execute(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{ .item }}.txt", TemplateName: "greeting.txt", Condition: "{{ .with_greeting }}", Each: "names"})
+	dispatcher := cli.NewDispatchCommand("dux").Add("show", cli.NewCommandBlueprintShow())
+	stdout := runWithOutputFormat(t, app, dispatcher, cli.OutputText, "dux", "show", "a")
+
+	expected := "  - name: {{ .item }}.txt\n    template: greeting.txt\n    if: {{ .with_greeting }}\n    each: names\n"
+	if !bytes.Contains(stdout, []byte(expected)) {
+		t.Fatalf("Expected %q in output:\n%s", expected, stdout)
+	}
+}
+
+func TestCLI_Run_rejects_unknown_output_formats_before_running_commands(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "Hello, {{ .name }}!")
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	ctx := cli.NewCLI(app, cli.WithOutput(stdout), cli.WithErrorOutput(stderr))
+	options := flag.NewFlagSet("dux", flag.ContinueOnError)
+	ctx.DefineFlags(options)
+	dispatcher := newDispatcher().WithOptions(options)
+
+	if status := ctx.Run(dispatcher, []string{"dux", "--output", "xml", "new", "a", "name=world"}); status != cli.ExitUsage {
+		t.Fatalf("Expected exit status %d, got %d: %s", cli.ExitUsage, status, stderr)
+	}
+	if !strings.Contains(stderr.String(), `Unknown output format: "xml"`) {
+		t.Fatalf("Expected unknown output format on stderr, got %q", stderr)
+	}
+	if exists, _ := app.FileSystem.Exists("world.txt"); exists {
+		t.Fatalf("Expected command not to run")
+	}
+}
@@ -14,3 +14,12 @@ func (cmd *parentCommand) CommandPath() string {
 	}
 	return cmd.parent.CommandPath()
 }
+
+// root returns the outermost dispatcher this command has been added to.
+func (cmd *parentCommand) root() *DispatchCommand {
+	root := cmd.parent
+	for root != nil && root.parent != nil {
+		root = root.parent
+	}
+	return root
+}
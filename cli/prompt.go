@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dhamidi/dux"
+)
+
+// Interactive reports whether the CLI may prompt the user for missing
+// values.
+func (cli *CLI) Interactive() bool {
+	return !cli.NoInput && *cli.terminal
+}
+
+// ResolveArguments checks the values in data against the arguments
+// declared by blueprint and fills in missing values.
+//
+// Missing values are prompted for if the CLI is interactive.
+// Otherwise defaults are used and an error listing all required
+// arguments without a value is returned.  Values are converted using
+// Blueprint.PrepareArguments.
+func (cli *CLI) ResolveArguments(blueprint *dux.Blueprint, data map[string]interface{}) error {
+	for _, arg := range blueprint.Arguments {
+		if value, found := data[arg.Name]; found {
+			if s, ok := value.(string); ok {
+				if err := arg.Validate(s); err != nil {
+					return &ValidationError{Err: err}
+				}
+			}
+			continue
+		}
+
+		if cli.Interactive() {
+			value, err := cli.Prompt(arg)
+			if err != nil {
+				return err
+			}
+			if value != "" {
+				data[arg.Name] = value
+			}
+		}
+	}
+
+	if err := blueprint.PrepareArguments(data); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return nil
+}
+
+// Prompt asks the user for a value for arg until a valid value has been
+// entered.  An empty answer selects the argument's default value and is
+// only accepted for optional arguments if there is no default.
+//
+// Prompts are written to the error output, so that they are shown even
+// if the output of dux is redirected.
+func (cli *CLI) Prompt(arg *dux.Argument) (string, error) {
+	for {
+		cli.showPrompt(arg)
+		answer, err := cli.lines.ReadString('\n')
+		if err != nil && (err != io.EOF || answer == "") {
+			fmt.Fprintf(cli.Err, "\n")
+			return "", fmt.Errorf("No value provided for %s: %s", arg.Name, err)
+		}
+		value, err := parseAnswer(arg, strings.TrimSpace(answer))
+		if err == nil {
+			return value, nil
+		}
+		fmt.Fprintf(cli.Err, "%s\n", err)
+	}
+}
+
+// showPrompt describes arg and asks for its value.
+func (cli *CLI) showPrompt(arg *dux.Argument) {
+	if arg.Description != "" {
+		fmt.Fprintf(cli.Err, "%s\n", arg.Description)
+	}
+	for i, choice := range arg.Choices {
+		fmt.Fprintf(cli.Err, "  %d) %s\n", i+1, choice)
+	}
+	label := arg.Name
+	if arg.Type != "" && arg.Type != dux.ArgumentTypeString {
+		label += " (" + arg.Type + ")"
+	}
+	if arg.Default != "" {
+		label += " [" + arg.Default + "]"
+	}
+	fmt.Fprintf(cli.Err, "%s: ", label)
+}
+
+// parseAnswer converts answer into a value for arg, accepting the
+// number of a choice in place of the choice itself.
+func parseAnswer(arg *dux.Argument, answer string) (string, error) {
+	if answer == "" {
+		if arg.Default != "" {
+			return arg.Default, nil
+		}
+		if arg.Required {
+			return "", fmt.Errorf("%s is required", arg.Name)
+		}
+		return "", nil
+	}
+	err := arg.Validate(answer)
+	if err == nil {
+		return answer, nil
+	}
+	if n, convErr := strconv.Atoi(answer); convErr == nil && len(arg.Choices) > 0 && n >= 1 && n <= len(arg.Choices) {
+		return arg.Choices[n-1], nil
+	}
+	return "", err
+}
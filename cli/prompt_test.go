@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestCLI_Run_prompts_for_missing_arguments_in_a_terminal(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Type: dux.ArgumentTypeIdentifier, Required: true, Description: "Who to greet"}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Choices: []string{"Hello", "Goodbye"}, Default: "Hello"}),
+	)
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	ctx := cli.NewCLI(app,
+		cli.WithInput(strings.NewReader("\nnot valid\nworld\n2\n")),
+		cli.WithOutput(stdout),
+		cli.WithErrorOutput(stderr),
+		cli.WithTerminal(true),
+	)
+
+	if status := ctx.Run(newDispatcher(), []string{"dux", "new", "a"}); status != cli.ExitOK {
+		t.Fatalf("Expected exit status %d, got %d:\n%s", cli.ExitOK, status, stderr)
+	}
+
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Goodbye, world!")
+	for _, expected := range []string{
+		"Who to greet\nname (identifier): ",
+		"name is required",
+		`name: "not valid" is not an identifier`,
+		"  2) Goodbye\ngreeting [Hello]: ",
+	} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("Expected %q in error output:\n%s", expected, stderr)
+		}
+	}
+	if strings.Contains(stdout.String(), "name (identifier): ") {
+		t.Errorf("Expected no prompts in output:\n%s", stdout)
+	}
+}
+
+func TestCLI_Run_lists_missing_arguments_without_a_terminal(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Required: true, Description: "Who to greet"}),
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Default: "Hello"}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "world\n", "dux", "new", "a")
+
+	h.AssertExitStatus(t, result, cli.ExitInvalid)
+	if !strings.Contains(result.Stderr, "Missing values for arguments:\n  name  Who to greet") {
+		t.Fatalf("Expected missing arguments on stderr, got %q", result.Stderr)
+	}
+}
+
+func TestCLI_Run_uses_defaults_without_a_terminal(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Default: "Hello"}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world")
+
+	h.AssertExitStatus(t, result, cli.ExitOK)
+	h.AssertFileContents(t, app.FileSystem, "world.txt", "Hello, world!")
+}
+
+func TestCLI_Run_rejects_invalid_argument_values(t *testing.T) {
+	app := h.NewAppWithBlueprint(t, dux.NewInMemoryFileSystem(), "{{ .greeting }}, {{ .name }}!",
+		h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Choices: []string{"Hello", "Goodbye"}}),
+	)
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "a", "name=world", "greeting=Hi")
+
+	h.AssertExitStatus(t, result, cli.ExitInvalid)
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Spec declares the positional arguments of a command together with
+// the text shown in help messages.  The flags of a command are
+// declared by its Options.
+//
+// Commands implementing HasSpec get their arguments validated before
+// Exec is called and their usage generated from the spec.
+type Spec struct {
+	Name        string          // the name under which the command is invoked
+	Summary     string          // a one line summary shown in command lists
+	Description string          // a longer explanation shown in help messages
+	Arguments   []*ArgumentSpec // positional arguments in order
+}
+
+// ArgumentSpec describes a single positional argument.
+type ArgumentSpec struct {
+	Name        string // the placeholder shown in usage, e.g. BLUEPRINT
+	Description string
+	Optional    bool      // the argument may be omitted
+	Repeated    bool      // the argument consumes all remaining arguments; only valid for the last argument
+	Complete    Completer // returns candidates for shell completion, optional
+}
+
+// HasSpec is implemented by Commands that declare their arguments.
+type HasSpec interface {
+	Spec() *Spec
+}
+
+// Synopsis returns the placeholders for all arguments, e.g.
+// "BLUEPRINT [VAR=VALUE...]".
+func (spec *Spec) Synopsis() string {
+	words := []string{}
+	for _, arg := range spec.Arguments {
+		word := arg.Name
+		if arg.Repeated {
+			word += "..."
+		}
+		if arg.Optional {
+			word = "[" + word + "]"
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// Validate checks that args provides all required arguments and no
+// more arguments than declared.
+func (spec *Spec) Validate(args []string) error {
+	for i, arg := range spec.Arguments {
+		if i >= len(args) {
+			if arg.Optional {
+				return nil
+			}
+			return usageErrorf("Missing argument %s", arg.Name)
+		}
+		if arg.Repeated {
+			return nil
+		}
+	}
+	if len(args) > len(spec.Arguments) {
+		return usageErrorf("Too many arguments: %s", strings.Join(args[len(spec.Arguments):], " "))
+	}
+	return nil
+}
+
+// WriteUsage writes a help message for a command invoked as path
+// accepting flags to out.
+func (spec *Spec) WriteUsage(out io.Writer, path string, flags *flag.FlagSet) {
+	usage := []string{path}
+	if flags != nil && hasFlags(flags) {
+		usage = append(usage, "[OPTIONS]")
+	}
+	if synopsis := spec.Synopsis(); synopsis != "" {
+		usage = append(usage, synopsis)
+	}
+	fmt.Fprintf(out, "Usage: %s\n\n", strings.Join(usage, " "))
+
+	description := spec.Description
+	if description == "" {
+		description = spec.Summary
+	}
+	if description != "" {
+		fmt.Fprintf(out, "%s\n\n", strings.TrimSpace(description))
+	}
+
+	if len(spec.Arguments) > 0 {
+		fmt.Fprintf(out, "Arguments:\n")
+		width := 0
+		for _, arg := range spec.Arguments {
+			if len(arg.Name) > width {
+				width = len(arg.Name)
+			}
+		}
+		for _, arg := range spec.Arguments {
+			fmt.Fprintf(out, "  %-*s  %s\n", width, arg.Name, arg.Description)
+		}
+		fmt.Fprintf(out, "\n")
+	}
+
+	if flags != nil && hasFlags(flags) {
+		fmt.Fprintf(out, "Options:\n")
+		fmt.Fprintf(out, "%s\n", formatFlags(flags))
+	}
+}
+
+// hasFlags reports whether any flags are defined in flags.
+func hasFlags(flags *flag.FlagSet) bool {
+	found := false
+	flags.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+// formatFlags lists all flags in flags with their default values,
+// sorted by name.  Aliases of other flags are not listed.
+func formatFlags(flags *flag.FlagSet) string {
+	labels, descriptions := []string{}, []string{}
+	width := 0
+	flags.VisitAll(func(f *flag.Flag) {
+		if _, isAlias := f.Value.(*aliasFlag); isAlias {
+			return
+		}
+		label := "--" + f.Name
+		if f.DefValue != "" {
+			label += "=" + f.DefValue
+		}
+		if len(label) > width {
+			width = len(label)
+		}
+		labels = append(labels, label)
+		descriptions = append(descriptions, f.Usage)
+	})
+
+	out := new(bytes.Buffer)
+	for i, label := range labels {
+		fmt.Fprintf(out, "  %-*s  %s\n", width, label, descriptions[i])
+	}
+	return out.String()
+}
+
+// describe returns the one line summary of cmd, if it provides one.
+func describe(cmd Command) string {
+	if spec, ok := cmd.(HasSpec); ok {
+		return spec.Spec().Summary
+	}
+	if description, ok := cmd.(HasDescription); ok {
+		return description.Description()
+	}
+	return ""
+}
+
+// ShowUsage writes the help message of cmd to out, generating it from
+// the command's Spec if it has one.
+func ShowUsage(out io.Writer, cmd Command) {
+	if spec, ok := cmd.(HasSpec); ok {
+		s := spec.Spec()
+		path := s.Name
+		if parent, ok := cmd.(interface{ CommandPath() string }); ok && parent.CommandPath() != "" {
+			path = parent.CommandPath() + " " + s.Name
+		}
+		spec.Spec().WriteUsage(out, path, cmd.Options())
+		return
+	}
+	if usage, ok := cmd.(HasUsage); ok {
+		usage.ShowUsage(out)
+	}
+}
@@ -0,0 +1,78 @@
+package cli_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux/cli"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestSpec_Validate_checks_the_number_of_arguments(t *testing.T) {
+	spec := &cli.Spec{
+		Name: "example",
+		Arguments: []*cli.ArgumentSpec{
+			{Name: "FIRST"},
+			{Name: "REST", Optional: true, Repeated: true},
+		},
+	}
+
+	if err := spec.Validate([]string{}); err == nil || !strings.Contains(err.Error(), "FIRST") {
+		t.Fatalf("Expected missing FIRST, got %v", err)
+	}
+	if err := spec.Validate([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("Expected repeated arguments to be accepted, got %v", err)
+	}
+	if synopsis := spec.Synopsis(); synopsis != "FIRST [REST...]" {
+		t.Fatalf("Unexpected synopsis %q", synopsis)
+	}
+}
+
+func TestSpec_Validate_rejects_extra_arguments(t *testing.T) {
+	spec := &cli.Spec{Name: "example", Arguments: []*cli.ArgumentSpec{{Name: "ONLY"}}}
+
+	if err := spec.Validate([]string{"a", "b"}); err == nil {
+		t.Fatalf("Expected an error for too many arguments")
+	}
+}
+
+func TestCLI_Run_shows_generated_usage_for_missing_arguments(t *testing.T) {
+	app := h.NewApp()
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new")
+
+	h.AssertExitStatus(t, result, cli.ExitUsage)
+	if !strings.Contains(result.Stderr, "Usage: dux new [OPTIONS] BLUEPRINT [ARGUMENT...]") {
+		t.Fatalf("Expected usage on stderr, got %q", result.Stderr)
+	}
+}
+
+func TestCLI_Run_does_not_show_usage_for_other_errors(t *testing.T) {
+	app := h.NewApp()
+
+	result := h.RunCLI(app, newDispatcher(), "", "dux", "new", "missing")
+
+	if result.ExitStatus == cli.ExitOK || result.ExitStatus == cli.ExitUsage {
+		t.Fatalf("Expected a failure other than a usage error, got exit status %d", result.ExitStatus)
+	}
+	if strings.Contains(result.Stderr, "Usage:") {
+		t.Fatalf("Expected no usage on stderr, got %q", result.Stderr)
+	}
+}
+
+func TestCLI_Run_shows_help_for_every_subcommand(t *testing.T) {
+	app := h.NewApp()
+
+	for _, args := range [][]string{
+		{"dux", "blueprint", "lint", "-h"},
+		{"dux", "help", "blueprint", "lint"},
+	} {
+		result := h.RunCLI(app, newDispatcher(), "", args...)
+		if result.ExitStatus != cli.ExitOK {
+			t.Fatalf("%v: expected exit status %d, got %d: %s", args, cli.ExitOK, result.ExitStatus, result.Stderr)
+		}
+		if !strings.Contains(result.Stdout, "Usage: dux blueprint lint BLUEPRINT") {
+			t.Fatalf("%v: expected usage on stdout, got %q", args, result.Stdout)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package dux
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Clock provides the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock reporting the system's wall clock time.
+type SystemClock struct{}
+
+// Now implements Clock by returning time.Now().
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// IDGenerator generates unique identifiers, e.g. for events.
+type IDGenerator interface {
+	NewID() string
+}
+
+// RandomIDGenerator generates random, hex encoded 128 bit identifiers.
+type RandomIDGenerator struct{}
+
+// NewID implements IDGenerator.
+func (RandomIDGenerator) NewID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"fmt"
+	"flag"
 	"os"
 
 	"github.com/dhamidi/dux"
@@ -10,19 +10,9 @@ import (
 
 func main() {
 	app := dux.NewApplication()
-	app.FileSystem = dux.NewOnDiskFileSystem()
+	app.FileSystem = dux.NewOnDiskFileSystem(".")
 	app.Init()
 	cliApp := cli.NewCLI(app)
-	app.EventStore.Subscribe(func(e *dux.Event) {
-		if e.Name == "blueprint-template-found" {
-			return
-		}
-		if e.Error == nil {
-			fmt.Printf("%s %v\n", e.Name, e.Payload)
-			return
-		}
-		cliApp.ShowError(e.Error)
-	})
 
 	blueprintCommands := cli.NewDispatchCommand("blueprint").
 		Describe("Inspect and edit blueprints").
@@ -30,18 +20,26 @@ func main() {
 		Add("file", cli.NewCommandBlueprintFile()).
 		Add("show", cli.NewCommandBlueprintShow()).
 		Add("describe", cli.NewCommandBlueprintDescribe()).
-		Add("create", cli.NewCommandBlueprintCreate())
+		Add("create", cli.NewCommandBlueprintCreate()).
+		Add("migrate", cli.NewCommandBlueprintMigrate()).
+		Add("export", cli.NewCommandBlueprintExport()).
+		Add("import", cli.NewCommandBlueprintImport()).
+		Add("add", cli.NewCommandBlueprintAdd()).
+		Add("update", cli.NewCommandBlueprintUpdate()).
+		Add("argument", cli.NewCommandBlueprintArgument()).
+		Add("hook", cli.NewCommandBlueprintHook()).
+		Add("lint", cli.NewCommandBlueprintLint()).
+		Add("test", cli.NewCommandBlueprintTest())
 
+	globalOptions := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	cliApp.DefineFlags(globalOptions)
 	dispatcher := cli.NewDispatchCommand(os.Args[0]).
+		WithOptions(globalOptions).
 		Add("new", cli.NewCommandNew()).
 		Add("list", cli.NewCommandList()).
-		Add("blueprint", blueprintCommands)
+		Add("blueprint", blueprintCommands).
+		Add("completion", cli.NewCommandCompletion()).
+		Add("__complete", cli.NewCommandComplete())
 
-	cmd, err := cliApp.Execute(dispatcher, os.Args)
-	if err != nil {
-		cliApp.ShowError(err)
-		if usage, ok := cmd.(cli.HasUsage); ok {
-			usage.ShowUsage(cliApp.Err)
-		}
-	}
+	os.Exit(cliApp.Run(dispatcher, os.Args))
 }
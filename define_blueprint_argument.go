@@ -0,0 +1,42 @@
+package dux
+
+// DefineBlueprintArgument declares an argument of a blueprint.
+type DefineBlueprintArgument struct {
+	BlueprintName string
+	Argument      *Argument
+}
+
+// CommandName implements Command
+func (c *DefineBlueprintArgument) CommandName() string { return "define-blueprint-argument" }
+
+// AddArgumentToBlueprint loads the blueprint from the store, adds the given argument and then stores the blueprint again.
+type AddArgumentToBlueprint struct {
+	store  Store
+	events EventStore
+}
+
+// NewAddArgumentToBlueprint returns a new command handler with the given store.
+func NewAddArgumentToBlueprint(store Store, events EventStore) *AddArgumentToBlueprint {
+	return &AddArgumentToBlueprint{store: store, events: events}
+}
+
+// Execute implements CommandHandler
+func (h *AddArgumentToBlueprint) Execute(command Command) error {
+	args := command.(*DefineBlueprintArgument)
+	blueprint := new(Blueprint)
+	if err := h.store.Get(args.BlueprintName, blueprint); err != nil {
+		return err
+	}
+	blueprint.DefineArgument(args.Argument)
+	err := h.store.Put(args.BlueprintName, blueprint)
+	if err == nil {
+		h.events.Emit(&Event{
+			Name: "blueprint-argument-defined",
+			Payload: EventPayload{
+				"blueprintName": args.BlueprintName,
+				"name":          args.Argument.Name,
+			},
+		})
+	}
+	return err
+}
@@ -1,10 +1,19 @@
 package dux
 
+import (
+	"fmt"
+	"os"
+)
+
 // DefineBlueprintFile defines a template that should be associated with the blueprint.
 type DefineBlueprintFile struct {
 	BlueprintName string
 	FileName      string
 	TemplateName  string
+	Mode          os.FileMode // Permissions of the generated file; zero means DefaultFileMode
+	Action        string      // FileActionCopy or FileActionRender; empty means detecting binary templates
+	Condition     string      // Template deciding whether the file is generated; empty means always
+	Each          string      // Name of a list or map for which to generate one file per element
 }
 
 // CommandName implements Command
@@ -24,11 +33,20 @@ func NewAddFileToBlueprint(store Store, events EventStore) *AddFileToBlueprint {
 // Execute implements CommandHandler
 func (h *AddFileToBlueprint) Execute(command Command) error {
 	args := command.(*DefineBlueprintFile)
+	if args.Action != "" && args.Action != FileActionCopy && args.Action != FileActionRender {
+		return fmt.Errorf("invalid action %q, expected %q or %q", args.Action, FileActionCopy, FileActionRender)
+	}
 	blueprint := new(Blueprint)
 	if err := h.store.Get(args.BlueprintName, blueprint); err != nil {
 		return err
 	}
 	blueprint.DefineFile(args.FileName, args.TemplateName)
+	if args.Mode != 0 {
+		blueprint.SetFileMode(args.FileName, args.Mode)
+	}
+	blueprint.SetFileAction(args.FileName, args.Action)
+	blueprint.SetFileCondition(args.FileName, args.Condition)
+	blueprint.SetFileIteration(args.FileName, args.Each)
 	err := h.store.Put(args.BlueprintName, blueprint)
 	if err == nil {
 		h.events.Emit(&Event{
@@ -37,6 +55,10 @@ func (h *AddFileToBlueprint) Execute(command Command) error {
 				"blueprintName": args.BlueprintName,
 				"filename":      args.FileName,
 				"templateName":  args.TemplateName,
+				"mode":          blueprint.FileMode(args.FileName),
+				"action":        args.Action,
+				"condition":     args.Condition,
+				"each":          args.Each,
 			},
 		})
 	}
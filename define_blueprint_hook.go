@@ -0,0 +1,43 @@
+package dux
+
+// DefineBlueprintHook adds a command that is run after installing the
+// files generated by a blueprint.
+type DefineBlueprintHook struct {
+	BlueprintName string
+	Command       string
+}
+
+// CommandName implements Command
+func (c *DefineBlueprintHook) CommandName() string { return "define-blueprint-hook" }
+
+// AddHookToBlueprint loads the blueprint from the store, adds the given hook and then stores the blueprint again.
+type AddHookToBlueprint struct {
+	store  Store
+	events EventStore
+}
+
+// NewAddHookToBlueprint returns a new command handler with the given store.
+func NewAddHookToBlueprint(store Store, events EventStore) *AddHookToBlueprint {
+	return &AddHookToBlueprint{store: store, events: events}
+}
+
+// Execute implements CommandHandler
+func (h *AddHookToBlueprint) Execute(command Command) error {
+	args := command.(*DefineBlueprintHook)
+	blueprint := new(Blueprint)
+	if err := h.store.Get(args.BlueprintName, blueprint); err != nil {
+		return err
+	}
+	blueprint.AddPostInstallHook(args.Command)
+	err := h.store.Put(args.BlueprintName, blueprint)
+	if err == nil {
+		h.events.Emit(&Event{
+			Name: "blueprint-hook-added",
+			Payload: EventPayload{
+				"blueprintName": args.BlueprintName,
+				"command":       args.Command,
+			},
+		})
+	}
+	return err
+}
@@ -32,7 +32,7 @@ func NewStoreBlueprintTemplate(fs FileSystem, events EventStore) *StoreBlueprint
 // Execute implements CommandHandler
 func (h *StoreBlueprintTemplate) Execute(command Command) error {
 	args := command.(*DefineBlueprintTemplate)
-	destinationFile := filepath.Join("blueprints", args.BlueprintName, "templates", args.TemplateName)
+	destinationFile := filepath.Join(BlueprintTemplateDirectory(args.BlueprintName), args.TemplateName)
 	out, err := h.fs.Create(destinationFile)
 	if err != nil {
 		return err
@@ -0,0 +1,120 @@
+package dux
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines shown around changes by Diff.
+const diffContextLines = 3
+
+// diffOp is a single line in a diff, prefixed by ' ', '-' or '+'.
+type diffOp struct {
+	kind    byte
+	line    string
+	oldLine int
+	newLine int
+}
+
+// Diff returns a unified diff between oldText and newText, labelling
+// the two versions with oldName and newName.  If both texts are
+// identical, the empty string is returned.
+func Diff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	ops := diffLines(splitLines(oldText), splitLines(newText))
+
+	out := new(bytes.Buffer)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", oldName, newName)
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		from := start - diffContextLines
+		if from < 0 {
+			from = 0
+		}
+
+		// extend the hunk until there are more than twice the context lines without changes
+		end, unchanged := start, 0
+		for end < len(ops) && unchanged <= 2*diffContextLines {
+			if ops[end].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			end++
+		}
+		if unchanged > diffContextLines {
+			end -= unchanged - diffContextLines
+		}
+
+		oldCount, newCount := 0, 0
+		for _, op := range ops[from:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", ops[from].oldLine, oldCount, ops[from].newLine, newCount)
+		for _, op := range ops[from:end] {
+			fmt.Fprintf(out, "%c%s\n", op.kind, op.line)
+		}
+		start = end
+	}
+
+	return out.String()
+}
+
+// splitLines splits text into lines, ignoring a trailing newline.
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes the edit script turning a into b based on their longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := []diffOp{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], oldLine: i + 1, newLine: j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', line: a[i], oldLine: i + 1, newLine: j + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j], oldLine: i + 1, newLine: j + 1})
+			j++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,33 @@
+package dux_test
+
+import (
+	"testing"
+
+	"github.com/dhamidi/dux"
+)
+
+func TestDiff_returns_empty_string_for_identical_texts(t *testing.T) {
+	if diff := dux.Diff("a", "b", "x\ny\n", "x\ny\n"); diff != "" {
+		t.Fatalf("Expected no diff, got %q", diff)
+	}
+}
+
+func TestDiff_shows_changed_lines_with_context(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	newText := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n"
+	expected := `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`
+	if diff := dux.Diff("a", "b", oldText, newText); diff != expected {
+		t.Fatalf("Expected diff:\n%s\nGot:\n%s", expected, diff)
+	}
+}
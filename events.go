@@ -1,10 +1,14 @@
 package dux
 
+import "time"
+
 // EventPayload is an unordered set of key-value pairs that carries event-specific information
 type EventPayload map[string]interface{}
 
 // Event documents an action that has taken place.
 type Event struct {
+	ID      string    // unique identifier assigned by the event store
+	Time    time.Time // time at which the event was emitted
 	Name    string
 	Payload EventPayload
 	Error   error
@@ -0,0 +1,172 @@
+package dux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ExportBlueprint writes a blueprint together with its templates
+// as a gzip compressed tar archive to Destination.
+type ExportBlueprint struct {
+	BlueprintName string
+	Destination   io.Writer
+}
+
+// CommandName implements Command
+func (c *ExportBlueprint) CommandName() string { return "export-blueprint" }
+
+// ExportBlueprintToArchive reads a blueprint from the store and its
+// templates from the file system and bundles them in an archive.
+type ExportBlueprintToArchive struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+}
+
+// NewExportBlueprintToArchive returns a new command handler with the given file system and store.
+func NewExportBlueprintToArchive(fs FileSystem, store Store, events EventStore) *ExportBlueprintToArchive {
+	return &ExportBlueprintToArchive{
+		fs:     fs,
+		store:  store,
+		events: events,
+	}
+}
+
+// Execute implements CommandHandler
+func (h *ExportBlueprintToArchive) Execute(command Command) error {
+	args := command.(*ExportBlueprint)
+	blueprint := new(Blueprint)
+	if err := h.store.Get(args.BlueprintName, blueprint); err != nil {
+		return err
+	}
+
+	definition, err := json.MarshalIndent(blueprint, "", "  ")
+	if err != nil {
+		return err
+	}
+	entries := map[string][]byte{archiveDefinitionPath: definition}
+	modes := map[string]os.FileMode{}
+	if err := h.collectDirectory(BlueprintTemplateDirectory(blueprint.Name), archiveTemplatesDir, entries, modes); err != nil {
+		return err
+	}
+	if err := h.collectDirectory(BlueprintSkeletonDirectory(blueprint.Name), archiveSkeletonDir, entries, modes); err != nil {
+		return err
+	}
+
+	manifest := &BlueprintManifest{
+		Version:       BlueprintArchiveVersion,
+		Name:          blueprint.Name,
+		SchemaVersion: blueprint.SchemaVersion,
+		Files:         map[string]string{},
+	}
+	for p, contents := range entries {
+		manifest.Files[p] = fileChecksum(contents)
+	}
+	if len(modes) > 0 {
+		manifest.Modes = modes
+	}
+	manifest.Checksum = manifest.ComputeChecksum()
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := writeArchive(args.Destination, manifestData, entries, manifest); err != nil {
+		return err
+	}
+
+	h.events.Emit(&Event{
+		Name: "blueprint-exported",
+		Payload: EventPayload{
+			"name":     blueprint.Name,
+			"files":    len(entries),
+			"checksum": manifest.Checksum,
+		},
+	})
+	return nil
+}
+
+// collectDirectory reads all files below templateDir into entries,
+// storing them below archiveDir.  Permissions other than
+// DefaultFileMode are recorded in modes.
+func (h *ExportBlueprintToArchive) collectDirectory(templateDir, archiveDir string, entries map[string][]byte, modes map[string]os.FileMode) error {
+	if exists, err := h.fs.Exists(templateDir); err != nil || !exists {
+		return err
+	}
+
+	return h.fs.Walk(templateDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(templateDir, p)
+		if err != nil {
+			return err
+		}
+		f, err := h.fs.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		contents, err := ioutil.ReadAll(f)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(filepath.Join(archiveDir, relative))
+		entries[name] = contents
+		if mode := info.Mode().Perm(); mode != DefaultFileMode {
+			modes[name] = mode
+		}
+		return nil
+	})
+}
+
+// writeArchive writes the encoded manifest followed by all entries in
+// lexical order as a gzip compressed tar archive to out, using the
+// permissions recorded in manifest.
+func writeArchive(out io.Writer, manifestData []byte, entries map[string][]byte, manifest *BlueprintManifest) error {
+	compressed := gzip.NewWriter(out)
+	archive := tar.NewWriter(compressed)
+
+	paths := []string{}
+	for p := range entries {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	write := func(name string, contents []byte, mode os.FileMode) error {
+		header := &tar.Header{
+			Name: name,
+			Mode: int64(mode),
+			Size: int64(len(contents)),
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := io.Copy(archive, bytes.NewReader(contents))
+		return err
+	}
+
+	if err := write(archiveManifestPath, manifestData, DefaultFileMode); err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if err := write(p, entries[p], manifest.FileMode(p)); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
@@ -1,24 +1,29 @@
 package dux
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // FileSystem encodes basic operations that can be performed on a file
 // system.
+//
+// Any errors returned by the methods of a FileSystem will be of type
+// *FileSystemError.
 type FileSystem interface {
 	// Open opens a file for reading.
-	//
-	// Any errors returned will be of type *FileSystemError
 	Open(filename string) (io.ReadCloser, error)
 
 	// Create opens a file for writing
-	//
-	// Any errors returned will be of type *FileSystemError
 	Create(filename string) (io.WriteCloser, error)
 
 	// List returns the names of all files in directory d
@@ -26,8 +31,37 @@ type FileSystem interface {
 
 	// Rename renames a file from oldpath to newpath.
 	Rename(oldpath, newpath string) error
+
+	// Stat returns information about the file or directory at
+	// filename.
+	Stat(filename string) (os.FileInfo, error)
+
+	// Exists returns true if a file or directory exists at
+	// filename.  The returned error is only non-nil if the
+	// existence of the file cannot be determined.
+	Exists(filename string) (bool, error)
+
+	// Remove removes a file or an empty directory.
+	Remove(filename string) error
+
+	// Chmod changes the permissions of filename to mode.
+	Chmod(filename string, mode os.FileMode) error
+
+	// Walk calls walkFn for every file and directory below root,
+	// including root itself, in lexical order.  The semantics of
+	// walkFn are the same as for filepath.Walk.
+	Walk(root string, walkFn filepath.WalkFunc) error
 }
 
+// DefaultFileMode are the permissions used for newly created files.
+const DefaultFileMode os.FileMode = 0644
+
+// DefaultDirMode are the permissions used for newly created directories.
+const DefaultDirMode os.FileMode = 0755
+
+// ExecutableFileMode are the permissions used for files that should be executable.
+const ExecutableFileMode os.FileMode = 0755
+
 // FileSystemError wraps errors returned by a FileSystem
 type FileSystemError struct {
 	Op   string
@@ -54,15 +88,53 @@ func (err *FileSystemError) Error() string {
 	return fmt.Sprintf("%s @ %s: %s", err.Op, err.Path, err.Err)
 }
 
+// Unwrap returns the implementation-specific error wrapped by err.
+func (err *FileSystemError) Unwrap() error {
+	return err.Err
+}
+
+// IsNotExist returns true if err indicates that a file does not exist.
+func IsNotExist(err error) bool {
+	if fsErr, ok := err.(*FileSystemError); ok {
+		err = fsErr.Err
+	}
+	return os.IsNotExist(err)
+}
+
+// inMemoryFile holds the contents and metadata of a single file in
+// an InMemoryFileSystem.
+type inMemoryFile struct {
+	buffer *bytes.Buffer
+	mode   os.FileMode
+}
+
+// inMemoryFileInfo implements os.FileInfo for files and directories
+// in an InMemoryFileSystem.
+type inMemoryFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (fi *inMemoryFileInfo) Name() string       { return fi.name }
+func (fi *inMemoryFileInfo) Size() int64        { return fi.size }
+func (fi *inMemoryFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *inMemoryFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi *inMemoryFileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *inMemoryFileInfo) Sys() interface{}   { return nil }
+
 // InMemoryFileSystem implements FileSystem with buffers in RAM.
+//
+// Directories are not stored explicitly, but exist implicitly as
+// long as they contain at least one file.
 type InMemoryFileSystem struct {
-	files map[string]*bytes.Buffer
+	files map[string]*inMemoryFile
 }
 
 // NewInMemoryFileSystem constructs a new file system with empty buffers
 func NewInMemoryFileSystem() *InMemoryFileSystem {
 	return &InMemoryFileSystem{
-		files: map[string]*bytes.Buffer{},
+		files: map[string]*inMemoryFile{},
 	}
 }
 
@@ -83,19 +155,27 @@ func (nop *nopWriteCloser) Close() error {
 // Open returns a the buffer at the given path.  If no buffer is
 // found, an error is returned.
 func (fs *InMemoryFileSystem) Open(filename string) (io.ReadCloser, error) {
-	buffer, found := fs.files[filename]
+	file, found := fs.files[filepath.Clean(filename)]
 	if !found {
-		return nil, NewFileSystemError("open", filename, fmt.Errorf("file not found"))
+		return nil, NewFileSystemError("open", filename, os.ErrNotExist)
 	}
 
-	return ioutil.NopCloser(buffer), nil
+	return ioutil.NopCloser(bytes.NewReader(file.buffer.Bytes())), nil
 }
 
-// Create creates a new buffer at the given path.  It never returns an error
+// Create creates a new buffer at the given path.  Creating a file at
+// a path that is used as a directory results in an error.
 func (fs *InMemoryFileSystem) Create(filename string) (io.WriteCloser, error) {
-	buffer := bytes.NewBufferString("")
-	fs.files[filename] = buffer
-	return NopWriteCloser(buffer), nil
+	filename = filepath.Clean(filename)
+	if fs.isDir(filename) {
+		return nil, NewFileSystemError("create", filename, fmt.Errorf("is a directory"))
+	}
+	file := &inMemoryFile{
+		buffer: bytes.NewBufferString(""),
+		mode:   DefaultFileMode,
+	}
+	fs.files[filename] = file
+	return NopWriteCloser(file.buffer), nil
 }
 
 // List returns all file names one hierarchy level below the directory d
@@ -111,7 +191,271 @@ func (fs *InMemoryFileSystem) List(d string) ([]string, error) {
 
 // Rename associates a buffer with a new path
 func (fs *InMemoryFileSystem) Rename(oldpath, newpath string) error {
-	fs.files[newpath] = fs.files[oldpath]
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	file, found := fs.files[oldpath]
+	if !found {
+		return NewFileSystemError("rename", oldpath, os.ErrNotExist)
+	}
+	if fs.isDir(newpath) {
+		return NewFileSystemError("rename", newpath, fmt.Errorf("is a directory"))
+	}
 	delete(fs.files, oldpath)
+	fs.files[newpath] = file
+	return nil
+}
+
+// Stat returns information about the file or implicit directory at filename.
+func (fs *InMemoryFileSystem) Stat(filename string) (os.FileInfo, error) {
+	filename = filepath.Clean(filename)
+	if file, found := fs.files[filename]; found {
+		return &inMemoryFileInfo{
+			name: filepath.Base(filename),
+			size: int64(file.buffer.Len()),
+			mode: file.mode,
+		}, nil
+	}
+
+	if fs.isDir(filename) {
+		return &inMemoryFileInfo{
+			name: filepath.Base(filename),
+			mode: os.ModeDir | DefaultDirMode,
+		}, nil
+	}
+
+	return nil, NewFileSystemError("stat", filename, os.ErrNotExist)
+}
+
+// Exists returns true if a file or directory exists at filename.  It never returns an error.
+func (fs *InMemoryFileSystem) Exists(filename string) (bool, error) {
+	filename = filepath.Clean(filename)
+	_, found := fs.files[filename]
+	return found || fs.isDir(filename), nil
+}
+
+// Remove deletes the buffer at filename.
+//
+// Since directories only exist as long as they contain files,
+// removing a directory always fails.
+func (fs *InMemoryFileSystem) Remove(filename string) error {
+	filename = filepath.Clean(filename)
+	if _, found := fs.files[filename]; !found {
+		if fs.isDir(filename) {
+			return NewFileSystemError("remove", filename, fmt.Errorf("directory not empty"))
+		}
+		return NewFileSystemError("remove", filename, os.ErrNotExist)
+	}
+	delete(fs.files, filename)
+	return nil
+}
+
+// Chmod sets the permission bits of the file at filename.
+func (fs *InMemoryFileSystem) Chmod(filename string, mode os.FileMode) error {
+	file, found := fs.files[filepath.Clean(filename)]
+	if !found {
+		return NewFileSystemError("chmod", filename, os.ErrNotExist)
+	}
+	file.mode = mode.Perm()
+	return nil
+}
+
+// Walk visits root and all files and directories below it in the
+// same order as filepath.Walk: the entries of every directory are
+// visited in lexical order, each directory followed by its contents.
+func (fs *InMemoryFileSystem) Walk(root string, walkFn filepath.WalkFunc) error {
+	root = filepath.Clean(root)
+	info, err := fs.Stat(root)
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		err = fs.walk(root, info, walkFn)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+// walk visits path and, if it is a directory, its contents
+// recursively.  Like filepath.Walk, returning filepath.SkipDir for a
+// file skips the remaining entries of its directory.
+func (fs *InMemoryFileSystem) walk(path string, info os.FileInfo, walkFn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return walkFn(path, info, nil)
+	}
+	if err := walkFn(path, info, nil); err != nil {
+		return err
+	}
+
+	for _, name := range fs.entries(path) {
+		entry := filepath.Join(path, name)
+		entryInfo, err := fs.Stat(entry)
+		if err != nil {
+			if err := walkFn(entry, entryInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		if err := fs.walk(entry, entryInfo, walkFn); err != nil {
+			if !entryInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
 	return nil
 }
+
+// entries returns the sorted names of the files and directories
+// directly below dir.
+func (fs *InMemoryFileSystem) entries(dir string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for filename := range fs.files {
+		if !isBelow(dir, filename) {
+			continue
+		}
+		relative, err := filepath.Rel(dir, filename)
+		if err != nil {
+			continue
+		}
+		name := strings.SplitN(relative, string(filepath.Separator), 2)[0]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isDir returns true if there is at least one file below dir.
+func (fs *InMemoryFileSystem) isDir(dir string) bool {
+	for filename := range fs.files {
+		if isBelow(dir, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBelow returns true if path is located somewhere below dir.
+func isBelow(dir, path string) bool {
+	if dir == "." {
+		return path != "." && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// ReadFile reads the whole contents of filename in fs.
+func ReadFile(fs FileSystem, filename string) ([]byte, error) {
+	in, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return ioutil.ReadAll(in)
+}
+
+// binarySniffLength is the number of bytes at the start of a file
+// which are inspected to decide whether it is binary.
+const binarySniffLength = 8000
+
+// isBinary reports whether contents look like binary data rather than
+// text, that is whether the first binarySniffLength bytes contain a
+// NUL byte or invalid UTF-8.
+func isBinary(contents []byte) bool {
+	sample := contents
+	if len(sample) > binarySniffLength {
+		sample = sample[:binarySniffLength]
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	if len(sample) == binarySniffLength {
+		// The sample might end in the middle of a character.
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return !utf8.Valid(sample)
+}
+
+// copyFile streams the contents of source to destination in fs without
+// holding the whole file in memory.  Unless force is true, the file is
+// only copied if it is binary and exists.  The returned boolean
+// reports whether the file has been copied.
+func copyFile(fs FileSystem, source, destination string, force bool) (bool, error) {
+	in, err := fs.Open(source)
+	if err != nil {
+		if !force && IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer in.Close()
+
+	reader := bufio.NewReaderSize(in, binarySniffLength)
+	if !force {
+		head, _ := reader.Peek(binarySniffLength)
+		if !isBinary(head) {
+			return false, nil
+		}
+	}
+
+	out, err := fs.Create(destination)
+	if err != nil {
+		return false, err
+	}
+	_, err = io.Copy(out, reader)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fs.Remove(destination)
+	}
+	return err == nil, err
+}
+
+// sourceFileMode returns the permissions of filename in fs, or
+// DefaultFileMode if they cannot be determined.
+func sourceFileMode(fs FileSystem, filename string) os.FileMode {
+	info, err := fs.Stat(filename)
+	if err != nil || info.IsDir() {
+		return DefaultFileMode
+	}
+	return info.Mode().Perm()
+}
+
+// sameContents reports whether the files a and b in fs have the same
+// contents, reading both files in chunks.
+func sameContents(fs FileSystem, a, b string) (bool, error) {
+	fileA, err := fs.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fileA.Close()
+	fileB, err := fs.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fileB.Close()
+
+	bufA, bufB := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		n, errA := io.ReadFull(fileA, bufA)
+		m, errB := io.ReadFull(fileB, bufB)
+		if !bytes.Equal(bufA[:n], bufB[:m]) {
+			return false, nil
+		}
+		doneA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		doneB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if errA != nil && !doneA {
+			return false, errA
+		}
+		if errB != nil && !doneB {
+			return false, errB
+		}
+		if doneA || doneB {
+			return doneA && doneB, nil
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package dux_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestInMemoryFileSystem_Stat_reports_files_and_directories(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	f, _ := fs.Create("a/b/c")
+	fmt.Fprintf(f, "hello")
+	f.Close()
+
+	info, err := fs.Stat("a/b/c")
+	if err != nil {
+		t.Fatalf("fs.Stat: %s", err)
+	}
+	if info.IsDir() || info.Size() != 5 || info.Mode() != dux.DefaultFileMode {
+		t.Fatalf("Unexpected file info: dir=%v size=%d mode=%s", info.IsDir(), info.Size(), info.Mode())
+	}
+
+	info, err = fs.Stat("a/b")
+	if err != nil {
+		t.Fatalf("fs.Stat: %s", err)
+	}
+	if !info.IsDir() {
+		t.Fatalf("Expected %q to be a directory", "a/b")
+	}
+}
+
+func TestInMemoryFileSystem_Stat_returns_a_FileSystemError_for_missing_files(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	_, err := fs.Stat("missing")
+	if _, ok := err.(*dux.FileSystemError); !ok {
+		t.Fatalf("Expected *dux.FileSystemError, got %T", err)
+	}
+	if !dux.IsNotExist(err) {
+		t.Fatalf("Expected IsNotExist(%s) to be true", err)
+	}
+}
+
+func TestInMemoryFileSystem_Exists_and_Remove(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	f, _ := fs.Create("a/b")
+	f.Close()
+
+	if exists, _ := fs.Exists("a/b"); !exists {
+		t.Fatalf("Expected %q to exist", "a/b")
+	}
+	if exists, _ := fs.Exists("a"); !exists {
+		t.Fatalf("Expected directory %q to exist", "a")
+	}
+	if err := fs.Remove("a"); err == nil {
+		t.Fatalf("Expected removing non-empty directory to fail")
+	}
+	if err := fs.Remove("a/b"); err != nil {
+		t.Fatalf("fs.Remove: %s", err)
+	}
+	if exists, _ := fs.Exists("a"); exists {
+		t.Fatalf("Expected %q to be gone after removing its only file", "a")
+	}
+}
+
+func TestInMemoryFileSystem_Rename_preserves_permissions(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	f, _ := fs.Create("script")
+	f.Close()
+	if err := fs.Chmod("script", dux.ExecutableFileMode); err != nil {
+		t.Fatalf("fs.Chmod: %s", err)
+	}
+	if err := fs.Rename("script", "bin/script"); err != nil {
+		t.Fatalf("fs.Rename: %s", err)
+	}
+	info, err := fs.Stat("bin/script")
+	if err != nil {
+		t.Fatalf("fs.Stat: %s", err)
+	}
+	if got, want := info.Mode(), dux.ExecutableFileMode; got != want {
+		t.Fatalf("Expected mode %s, got %s", want, got)
+	}
+}
+
+func TestInMemoryFileSystem_Walk_visits_files_and_directories_in_lexical_order(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	for _, name := range []string{"root/b/c", "root/a", "root/b/d/e", "other"} {
+		f, _ := fs.Create(name)
+		f.Close()
+	}
+
+	visited := []string{}
+	err := fs.Walk("root", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		visited = append(visited, path)
+		if path == filepath.Join("root", "b", "d") {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("fs.Walk: %s", err)
+	}
+
+	expected := []string{"root", "root/a", "root/b", "root/b/c", "root/b/d"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Fatalf("Expected to visit %v, got %v", expected, visited)
+	}
+}
+
+func TestInMemoryFileSystem_Walk_visits_directories_before_siblings_sorting_after_them(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	for _, name := range []string{"r/b/c", "r/b.txt", "r/b-x"} {
+		f, _ := fs.Create(name)
+		f.Close()
+	}
+
+	visited := []string{}
+	err := fs.Walk("r", func(path string, info os.FileInfo, err error) error {
+		visited = append(visited, path)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("fs.Walk: %s", err)
+	}
+
+	expected := []string{"r", "r/b", "r/b/c", "r/b-x", "r/b.txt"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Fatalf("Expected to visit %v, got %v", expected, visited)
+	}
+}
+
+func TestInMemoryFileSystem_Walk_visits_names_starting_with_two_dots_in_the_current_directory(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	for _, name := range []string{"..foo", "a"} {
+		f, _ := fs.Create(name)
+		f.Close()
+	}
+
+	visited := []string{}
+	err := fs.Walk(".", func(path string, info os.FileInfo, err error) error {
+		visited = append(visited, path)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("fs.Walk: %s", err)
+	}
+
+	expected := []string{".", "..foo", "a"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Fatalf("Expected to visit %v, got %v", expected, visited)
+	}
+}
+
+func TestApp_RenderBlueprint_applies_file_modes_defined_in_the_blueprint(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "#!/bin/sh"))
+	do(&dux.DefineBlueprintFile{
+		BlueprintName: "a",
+		FileName:      "run",
+		TemplateName:  "x.tmpl",
+		Mode:          dux.ExecutableFileMode,
+	})
+	do(h.RenderBlueprint("a"))
+	info, err := app.FileSystem.Stat("staging/run")
+	if err != nil {
+		t.Fatalf("Stat: %s", err)
+	}
+	if got, want := info.Mode(), dux.ExecutableFileMode; got != want {
+		t.Fatalf("Expected mode %s, got %s", want, got)
+	}
+}
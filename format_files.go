@@ -0,0 +1,186 @@
+package dux
+
+import (
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"io/ioutil"
+	"path/filepath"
+)
+
+// FormatFiles formats generated files using the formatter registered
+// for their extension.  Files without a formatter are left untouched.
+type FormatFiles struct {
+	Files []string
+}
+
+// CommandName implements Command
+func (c *FormatFiles) CommandName() string { return "format-files" }
+
+// Formatter rewrites the contents of generated files of a particular
+// type into a canonical form.
+type Formatter interface {
+	// Extension returns the file name extension, including the
+	// leading dot, of files handled by this formatter.
+	Extension() string
+
+	// Format returns the formatted version of source.
+	//
+	// Errors that can be attributed to a position in source are
+	// returned as *FormatError.
+	Format(source []byte) ([]byte, error)
+}
+
+// Formatters lists the formatters used by FormatFilesInFileSystem.
+// If multiple formatters handle the same extension, the first
+// formatter in this list wins.
+var Formatters = []Formatter{
+	&GoFormatter{},
+}
+
+// FormatterFor returns the formatter registered for the extension of
+// filename or nil, if there is none.
+func FormatterFor(filename string) Formatter {
+	extension := filepath.Ext(filename)
+	for _, formatter := range Formatters {
+		if formatter.Extension() == extension {
+			return formatter
+		}
+	}
+	return nil
+}
+
+// FormatError is returned when a generated file cannot be formatted,
+// usually because the template produced invalid syntax.
+type FormatError struct {
+	Filename string
+	Line     int    // The line on which the error occurred, or 0 if unknown
+	Column   int    // The column at which the error occurred, or 0 if unknown
+	Source   []byte // The unformatted contents of the file
+	Err      error
+}
+
+// Error implements the error interface
+func (err *FormatError) Error() string {
+	switch {
+	case err.Line > 0 && err.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", err.Filename, err.Line, err.Column, err.Err)
+	case err.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", err.Filename, err.Line, err.Err)
+	}
+	return fmt.Sprintf("%s: %s", err.Filename, err.Err)
+}
+
+// Unwrap returns the underlying error.
+func (err *FormatError) Unwrap() error { return err.Err }
+
+// GoFormatter implements Formatter using go/format.
+type GoFormatter struct{}
+
+// Extension implements Formatter
+func (f *GoFormatter) Extension() string { return ".go" }
+
+// Format implements Formatter
+func (f *GoFormatter) Format(source []byte) ([]byte, error) {
+	formatted, err := format.Source(source)
+	if err == nil {
+		return formatted, nil
+	}
+	formatErr := &FormatError{Err: err}
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		formatErr.Line = list[0].Pos.Line
+		formatErr.Column = list[0].Pos.Column
+		formatErr.Err = fmt.Errorf("%s", list[0].Msg)
+	}
+	return nil, formatErr
+}
+
+// FormatFilesInFileSystem formats files in place and emits a
+// "file-formatted" event for every file that has been formatted.
+//
+// If a file cannot be formatted, it is left as is and a
+// "file-format-failed" event is emitted, carrying the unformatted
+// source and the position of the error.
+type FormatFilesInFileSystem struct {
+	fs     FileSystem
+	events EventStore
+}
+
+// NewFormatFilesInFileSystem returns a new command handler
+func NewFormatFilesInFileSystem(fs FileSystem, events EventStore) *FormatFilesInFileSystem {
+	return &FormatFilesInFileSystem{
+		fs:     fs,
+		events: events,
+	}
+}
+
+// Execute implements CommandHandler
+func (h *FormatFilesInFileSystem) Execute(command Command) error {
+	args := command.(*FormatFiles)
+	for _, filename := range args.Files {
+		formatter := FormatterFor(filename)
+		if formatter == nil {
+			continue
+		}
+		if err := h.format(formatter, filename); err != nil {
+			payload := EventPayload{"filename": filename}
+			if formatErr, ok := err.(*FormatError); ok {
+				formatErr.Filename = filename
+				payload["source"] = string(formatErr.Source)
+				payload["line"] = formatErr.Line
+				payload["column"] = formatErr.Column
+			}
+			h.events.Emit(&Event{
+				Name:    "file-format-failed",
+				Error:   err,
+				Payload: payload,
+			})
+			continue
+		}
+		h.events.Emit(&Event{
+			Name: "file-formatted",
+			Payload: EventPayload{
+				"filename": filename,
+			},
+		})
+	}
+	return nil
+}
+
+// format replaces the contents of filename with its formatted version.
+func (h *FormatFilesInFileSystem) format(formatter Formatter, filename string) error {
+	in, err := h.fs.Open(filename)
+	if err != nil {
+		return err
+	}
+	source, err := ioutil.ReadAll(in)
+	in.Close()
+	if err != nil {
+		return err
+	}
+
+	formatted, err := formatter.Format(source)
+	if err != nil {
+		formatErr, ok := err.(*FormatError)
+		if !ok {
+			formatErr = &FormatError{Err: err}
+		}
+		formatErr.Source = source
+		return formatErr
+	}
+
+	mode := sourceFileMode(h.fs, filename)
+	out, err := h.fs.Create(filename)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(formatted)
+	out.Close()
+	if err != nil {
+		return err
+	}
+	if mode != DefaultFileMode {
+		return h.fs.Chmod(filename, mode)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package dux_test
+
+import (
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestFormatFilesInFileSystem_formats_go_files(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "main.go", "package main\nfunc main() {\n  println( \"hello\" )\n}\n")
+	writeFile(t, app.FileSystem, "notes.txt", "  unformatted  ")
+
+	h.FailOnExecuteError(t, app)(&dux.FormatFiles{Files: []string{"main.go", "notes.txt"}})
+
+	h.AssertFileContents(t, app.FileSystem, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+	h.AssertFileContents(t, app.FileSystem, "notes.txt", "  unformatted  ")
+	h.AssertEventCount(t, app.EventStore, "file-formatted", 1)
+	h.AssertEvent(t, app.EventStore, "file-formatted", dux.EventPayload{"filename": "main.go"})
+}
+
+func TestFormatFilesInFileSystem_reports_position_of_syntax_errors(t *testing.T) {
+	app := h.NewApp()
+	source := "package main\n\nfunc main() {\n\tprintln(\"hello\"\n}\n"
+	writeFile(t, app.FileSystem, "main.go", source)
+
+	h.FailOnExecuteError(t, app)(&dux.FormatFiles{Files: []string{"main.go"}})
+
+	h.AssertFileContents(t, app.FileSystem, "main.go", source)
+	events := h.Events(t, app.EventStore, "file-format-failed")
+	if len(events) != 1 {
+		t.Fatalf("Expected one file-format-failed event, got %d", len(events))
+	}
+	e := events[0]
+	if e.Payload["source"] != source {
+		t.Errorf("Expected unformatted source in payload, got %q", e.Payload["source"])
+	}
+	if e.Payload["line"] != 4 || e.Payload["column"] != 17 {
+		t.Errorf("Expected error at 4:17, got %v:%v", e.Payload["line"], e.Payload["column"])
+	}
+	formatErr, ok := e.Error.(*dux.FormatError)
+	if !ok {
+		t.Fatalf("Expected *dux.FormatError, got %T", e.Error)
+	}
+	if formatErr.Filename != "main.go" {
+		t.Errorf("Expected error for main.go, got %q", formatErr.Filename)
+	}
+}
+
+func TestFormatterFor_returns_formatter_by_extension(t *testing.T) {
+	if formatter := dux.FormatterFor("cmd/main.go"); formatter == nil || formatter.Extension() != ".go" {
+		t.Errorf("Expected Go formatter for main.go, got %v", formatter)
+	}
+	if formatter := dux.FormatterFor("README.md"); formatter != nil {
+		t.Errorf("Expected no formatter for README.md, got %v", formatter)
+	}
+}
@@ -0,0 +1,110 @@
+package dux
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// GitSource identifies a revision in a git repository from which blueprints can be installed.
+type GitSource struct {
+	URL string // A path or URL understood by git clone
+	Ref string // A branch, tag or commit; the remote's default branch if empty
+}
+
+// ParseGitSource parses a source of the form <path-or-url>[@ref].
+//
+// An "@" is only treated as the start of a ref if the text
+// following it is a valid ref, so that URLs such as
+// git@example.com:repo.git can be used without a ref.
+//
+// An error is returned if the URL or ref would be read as an option by
+// git.
+func ParseGitSource(source string) (*GitSource, error) {
+	result := parseGitSource(source)
+	return result, result.Validate()
+}
+
+// parseGitSource splits source into URL and ref.
+func parseGitSource(source string) *GitSource {
+	at := strings.LastIndex(source, "@")
+	if at < 0 {
+		return &GitSource{URL: source}
+	}
+
+	url, ref := source[:at], source[at+1:]
+	if ref == "" || strings.Contains(ref, ":") {
+		return &GitSource{URL: source}
+	}
+	if scheme := strings.Index(url, "://"); scheme >= 0 && !strings.Contains(url[scheme+3:], "/") {
+		// the "@" separates user information from the host
+		return &GitSource{URL: source}
+	}
+
+	return &GitSource{URL: url, Ref: ref}
+}
+
+// Validate returns an error if the URL or ref of s start with a dash,
+// since git would read them as options.
+func (s *GitSource) Validate() error {
+	if strings.HasPrefix(s.URL, "-") {
+		return fmt.Errorf("invalid git URL %q: must not start with \"-\"", s.URL)
+	}
+	if strings.HasPrefix(s.Ref, "-") {
+		return fmt.Errorf("invalid git ref %q: must not start with \"-\"", s.Ref)
+	}
+	return nil
+}
+
+// String returns the source in the format accepted by ParseGitSource.
+func (s *GitSource) String() string {
+	if s.Ref == "" {
+		return s.URL
+	}
+	return s.URL + "@" + s.Ref
+}
+
+// Git runs git commands using the git executable.
+type Git struct {
+	Executable string
+}
+
+// NewGit returns a Git that runs the git executable found in $PATH.
+func NewGit() *Git {
+	return &Git{Executable: "git"}
+}
+
+// Checkout clones the repository identified by source into dir,
+// checks out the requested ref and returns the full hash of the
+// checked out commit.
+func (g *Git) Checkout(source *GitSource, dir string) (string, error) {
+	if err := source.Validate(); err != nil {
+		return "", err
+	}
+	if _, err := g.run("", "clone", "--quiet", "--", source.URL, dir); err != nil {
+		return "", err
+	}
+	if source.Ref != "" {
+		if _, err := g.run(dir, "checkout", "--quiet", source.Ref, "--"); err != nil {
+			return "", err
+		}
+	}
+	commit, err := g.run(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+// run executes git with args in dir and returns its output.
+func (g *Git) run(dir string, args ...string) (string, error) {
+	cmd := exec.Command(g.Executable, args...)
+	cmd.Dir = dir
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,8 @@
+module github.com/dhamidi/dux
+
+go 1.21
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	gopkg.in/yaml.v3 v3.0.1
+)
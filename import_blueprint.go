@@ -0,0 +1,204 @@
+package dux
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// ImportBlueprint reads a blueprint archive created by
+// ExportBlueprint from Source and stores the blueprint and its
+// templates.
+type ImportBlueprint struct {
+	Source io.Reader
+	Force  bool // Overwrite an existing blueprint with the same name
+}
+
+// CommandName implements Command
+func (c *ImportBlueprint) CommandName() string { return "import-blueprint" }
+
+// ImportBlueprintFromArchive verifies a blueprint archive and, if it
+// is valid, writes its contents to the store and the file system.
+//
+// Nothing is written unless the archive is complete and all
+// checksums match.
+type ImportBlueprintFromArchive struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+}
+
+// NewImportBlueprintFromArchive returns a new command handler with the given file system and store.
+func NewImportBlueprintFromArchive(fs FileSystem, store Store, events EventStore) *ImportBlueprintFromArchive {
+	return &ImportBlueprintFromArchive{
+		fs:     fs,
+		store:  store,
+		events: events,
+	}
+}
+
+// Execute implements CommandHandler
+func (h *ImportBlueprintFromArchive) Execute(command Command) error {
+	args := command.(*ImportBlueprint)
+	manifest, entries, err := readArchive(args.Source)
+	if err != nil {
+		return err
+	}
+	if err := manifest.Verify(entries); err != nil {
+		return err
+	}
+
+	blueprint, err := decodeArchivedBlueprint(entries[archiveDefinitionPath])
+	if err != nil {
+		return fmt.Errorf("%s: %s", archiveDefinitionPath, err)
+	}
+	if blueprint.Name != manifest.Name {
+		return fmt.Errorf("blueprint name %q does not match name %q in manifest", blueprint.Name, manifest.Name)
+	}
+	if _, err := cleanArchivePath(blueprint.Name); err != nil || strings.Contains(blueprint.Name, "/") {
+		return fmt.Errorf("invalid blueprint name %q", blueprint.Name)
+	}
+
+	exists, err := h.store.Exists(blueprint.Name)
+	if err != nil {
+		return err
+	}
+	if exists && !args.Force {
+		return fmt.Errorf("blueprint %q already exists", blueprint.Name)
+	}
+
+	// Templates and skeleton files missing from the archive are
+	// removed, so that a forced import does not leave stale files
+	// behind.
+	templates, skeleton := map[string]string{}, map[string]string{}
+	for p, contents := range entries {
+		var files map[string]string
+		switch {
+		case strings.HasPrefix(p, archiveTemplatesDir+"/"):
+			files = templates
+		case strings.HasPrefix(p, archiveSkeletonDir+"/"):
+			files = skeleton
+		default:
+			continue
+		}
+		relative := p[strings.Index(p, "/")+1:]
+		files[filepath.FromSlash(relative)] = string(contents)
+	}
+	if err := replaceTemplates(h.fs, blueprint.Name, templates); err != nil {
+		return err
+	}
+	if err := replaceTree(h.fs, BlueprintSkeletonDirectory(blueprint.Name), skeleton); err != nil {
+		return err
+	}
+	for p := range entries {
+		var dir string
+		switch {
+		case strings.HasPrefix(p, archiveTemplatesDir+"/"):
+			dir = BlueprintTemplateDirectory(blueprint.Name)
+		case strings.HasPrefix(p, archiveSkeletonDir+"/"):
+			dir = BlueprintSkeletonDirectory(blueprint.Name)
+		default:
+			continue
+		}
+		relative := filepath.FromSlash(p[strings.Index(p, "/")+1:])
+		if err := h.fs.Chmod(filepath.Join(dir, relative), manifest.FileMode(p)); err != nil {
+			return err
+		}
+	}
+
+	if err := h.store.Put(blueprint.Name, blueprint); err != nil {
+		return err
+	}
+
+	h.events.Emit(&Event{
+		Name: "blueprint-imported",
+		Payload: EventPayload{
+			"name":      blueprint.Name,
+			"templates": len(templates),
+			"checksum":  manifest.Checksum,
+		},
+	})
+	return nil
+}
+
+// decodeArchivedBlueprint decodes the blueprint definition of an
+// archive, migrating it to the latest schema version.
+func decodeArchivedBlueprint(definition []byte) (*Blueprint, error) {
+	document := map[string]interface{}{}
+	if err := json.Unmarshal(definition, &document); err != nil {
+		return nil, err
+	}
+	if err := Migrate(document, BlueprintMigrations); err != nil {
+		return nil, err
+	}
+	blueprint := new(Blueprint)
+	return blueprint, reencode(document, blueprint)
+}
+
+// readArchive reads all entries from a gzip compressed tar archive.
+//
+// Entry paths are normalized and entries escaping the archive root
+// are rejected, as are entries larger than maxArchiveEntrySize and
+// archives larger than maxArchiveSize in total.  The manifest is
+// decoded and returned separately.
+func readArchive(in io.Reader) (*BlueprintManifest, map[string][]byte, error) {
+	decompressed, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer decompressed.Close()
+
+	var manifest *BlueprintManifest
+	entries := map[string][]byte{}
+	total := int64(0)
+	archive := tar.NewReader(decompressed)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, nil, fmt.Errorf("unsupported entry type for %q in archive", header.Name)
+		}
+		name, err := cleanArchivePath(header.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		contents, err := ioutil.ReadAll(io.LimitReader(archive, maxArchiveEntrySize+1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(contents) > maxArchiveEntrySize {
+			return nil, nil, fmt.Errorf("%s in archive is larger than %d bytes", name, maxArchiveEntrySize)
+		}
+		total += int64(len(contents))
+		if total > maxArchiveSize {
+			return nil, nil, fmt.Errorf("archive is larger than %d bytes", maxArchiveSize)
+		}
+
+		if name == archiveManifestPath {
+			manifest = new(BlueprintManifest)
+			if err := json.Unmarshal(contents, manifest); err != nil {
+				return nil, nil, fmt.Errorf("%s: %s", archiveManifestPath, err)
+			}
+			continue
+		}
+		entries[name] = contents
+	}
+
+	if manifest == nil {
+		return nil, nil, fmt.Errorf("archive does not contain %s", archiveManifestPath)
+	}
+	return manifest, entries, nil
+}
@@ -0,0 +1,73 @@
+package dux
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+)
+
+// InMemoryStore implements Store by keeping JSON encoded objects in
+// RAM.
+//
+// Encoding objects ensures that modifying an object after it has
+// been stored does not change the stored object.
+type InMemoryStore struct {
+	objects map[string][]byte
+}
+
+// NewInMemoryStore creates a new, empty store.
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		objects: map[string][]byte{},
+	}
+}
+
+// Get decodes the object stored under id into dest.
+func (s *InMemoryStore) Get(id string, dest interface{}) error {
+	data, found := s.objects[id]
+	if !found {
+		return &NotFoundError{ID: id}
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// Put encodes src and stores the result under id.
+func (s *InMemoryStore) Put(id string, src interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	s.objects[id] = data
+	return nil
+}
+
+// List returns all ids matching pattern in lexical order.
+func (s *InMemoryStore) List(pattern string) ([]string, error) {
+	result := []string{}
+	for id := range s.objects {
+		matches, err := filepath.Match(pattern, id)
+		if err != nil {
+			return []string{}, err
+		}
+		if matches {
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Delete removes the object stored under id.
+func (s *InMemoryStore) Delete(id string) error {
+	if _, found := s.objects[id]; !found {
+		return &NotFoundError{ID: id}
+	}
+	delete(s.objects, id)
+	return nil
+}
+
+// Exists returns true if an object is stored under id.  It never returns an error.
+func (s *InMemoryStore) Exists(id string) (bool, error) {
+	_, found := s.objects[id]
+	return found, nil
+}
@@ -1,15 +1,31 @@
 package dux
 
+import "fmt"
+
 // Install moves files from sources to destinations and emits events
 // about the progress.
+//
+// Existing destination files are left untouched unless Force is set.
 type Install struct {
 	Sources      []string
 	Destinations []string
+	Force        bool
 }
 
 // CommandName implements Command
 func (c *Install) CommandName() string { return "install" }
 
+// ConflictError indicates that a file could not be installed because
+// a different file already exists at its destination.
+type ConflictError struct {
+	Path string
+}
+
+// Error implements error
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s already exists with different contents", e.Path)
+}
+
 // InstallInFileSystem moves files in the given file system and emits
 // events about the progress.
 type InstallInFileSystem struct {
@@ -29,26 +45,76 @@ func NewInstallInFileSystem(fs FileSystem, events EventStore) *InstallInFileSyst
 func (h *InstallInFileSystem) Execute(command Command) error {
 	args := command.(*Install)
 	for i, source := range args.Sources {
-		err := h.fs.Rename(source, args.Destinations[i])
-		if err == nil {
+		destination := args.Destinations[i]
+		payload := EventPayload{
+			"from": source,
+			"to":   destination,
+		}
+		state, err := h.compare(source, destination)
+		if err != nil {
 			h.events.Emit(&Event{
-				Name: "file-renamed",
-				Payload: EventPayload{
-					"from": source,
-					"to":   args.Destinations[i],
-				},
+				Name:    "file-rename-failed",
+				Error:   err,
+				Payload: payload,
 			})
-		} else {
+			continue
+		}
+		switch state {
+		case fileIdentical:
+			h.fs.Remove(source)
 			h.events.Emit(&Event{
-				Name:  "file-rename-failed",
-				Error: err,
-				Payload: EventPayload{
-					"from": source,
-					"to":   args.Destinations[i],
-				},
+				Name:    "file-identical",
+				Payload: payload,
 			})
+			continue
+		case fileDifferent:
+			if !args.Force {
+				h.events.Emit(&Event{
+					Name:    "file-conflict",
+					Error:   &ConflictError{Path: destination},
+					Payload: payload,
+				})
+				continue
+			}
+			payload["overwritten"] = true
 		}
+
+		if err := h.fs.Rename(source, destination); err != nil {
+			h.events.Emit(&Event{
+				Name:    "file-rename-failed",
+				Error:   err,
+				Payload: payload,
+			})
+			continue
+		}
+		h.events.Emit(&Event{
+			Name:    "file-renamed",
+			Payload: payload,
+		})
 	}
 
 	return nil
 }
+
+const (
+	fileMissing = iota
+	fileIdentical
+	fileDifferent
+)
+
+// compare reports whether destination is missing, identical to source
+// or different from source.
+func (h *InstallInFileSystem) compare(source, destination string) (int, error) {
+	exists, err := h.fs.Exists(destination)
+	if err != nil || !exists {
+		return fileMissing, err
+	}
+	identical, err := sameContents(h.fs, source, destination)
+	if err != nil {
+		return fileMissing, err
+	}
+	if identical {
+		return fileIdentical, nil
+	}
+	return fileDifferent, nil
+}
@@ -41,3 +41,46 @@ func TestInstallInFileSystem_emits_an_event_for_file_it_failed_to_rename(t *test
 			"from": "staging/EXAMPLE",
 		})
 }
+
+func TestInstallInFileSystem_does_not_overwrite_files_with_different_contents(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "staging/EXAMPLE", "new")
+	writeFile(t, app.FileSystem, "example", "old")
+	do := h.FailOnExecuteError(t, app)
+	do(h.Install("staging/EXAMPLE", "example"))
+	h.AssertEvent(t, app.EventStore, "file-conflict",
+		dux.EventPayload{
+			"to":   "example",
+			"from": "staging/EXAMPLE",
+		})
+	h.AssertNoEvent(t, app.EventStore, "file-renamed", nil)
+	h.AssertFileContents(t, app.FileSystem, "example", "old")
+}
+
+func TestInstallInFileSystem_overwrites_files_when_forced(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "staging/EXAMPLE", "new")
+	writeFile(t, app.FileSystem, "example", "old")
+	do := h.FailOnExecuteError(t, app)
+	install := h.Install("staging/EXAMPLE", "example")
+	install.Force = true
+	do(install)
+	h.AssertEvent(t, app.EventStore, "file-renamed",
+		dux.EventPayload{
+			"to":          "example",
+			"overwritten": true,
+		})
+	h.AssertFileContents(t, app.FileSystem, "example", "new")
+}
+
+func TestInstallInFileSystem_reports_identical_files(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "staging/EXAMPLE", "same")
+	writeFile(t, app.FileSystem, "example", "same")
+	do := h.FailOnExecuteError(t, app)
+	do(h.Install("staging/EXAMPLE", "example"))
+	h.AssertEvent(t, app.EventStore, "file-identical",
+		dux.EventPayload{
+			"to": "example",
+		})
+}
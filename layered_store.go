@@ -0,0 +1,86 @@
+package dux
+
+import "sort"
+
+// LayeredStore implements Store by combining several stores.
+//
+// Layers are ordered by precedence: when reading an object, the first
+// layer containing the object wins.  All modifications are applied to
+// the first layer only, which means that deleting an object can make
+// an object with the same id in a lower layer visible.
+type LayeredStore struct {
+	layers []Store
+}
+
+// NewLayeredStore creates a new store from the given layers, with
+// the first layer having the highest precedence.
+func NewLayeredStore(layers ...Store) *LayeredStore {
+	return &LayeredStore{
+		layers: layers,
+	}
+}
+
+// Layers returns the stores making up this store in order of precedence.
+func (s *LayeredStore) Layers() []Store {
+	return s.layers
+}
+
+// Get loads the object identified by id from the first layer that contains it.
+func (s *LayeredStore) Get(id string, dest interface{}) error {
+	for _, layer := range s.layers {
+		err := layer.Get(id, dest)
+		if IsNotFound(err) {
+			continue
+		}
+		return err
+	}
+	return &NotFoundError{ID: id}
+}
+
+// Put stores src in the first layer.
+func (s *LayeredStore) Put(id string, src interface{}) error {
+	if len(s.layers) == 0 {
+		return &NotFoundError{ID: id}
+	}
+	return s.layers[0].Put(id, src)
+}
+
+// List returns the ids found in all layers in lexical order, without duplicates.
+func (s *LayeredStore) List(pattern string) ([]string, error) {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, layer := range s.layers {
+		ids, err := layer.List(pattern)
+		if err != nil {
+			return []string{}, err
+		}
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// Delete removes the object identified by id from the first layer.
+func (s *LayeredStore) Delete(id string) error {
+	if len(s.layers) == 0 {
+		return &NotFoundError{ID: id}
+	}
+	return s.layers[0].Delete(id)
+}
+
+// Exists returns true if any layer contains an object with the given id.
+func (s *LayeredStore) Exists(id string) (bool, error) {
+	for _, layer := range s.layers {
+		exists, err := layer.Exists(id)
+		if err != nil || exists {
+			return exists, err
+		}
+	}
+	return false, nil
+}
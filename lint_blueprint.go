@@ -0,0 +1,479 @@
+package dux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// LintBlueprint checks a blueprint and its templates for problems.
+type LintBlueprint struct {
+	BlueprintName string
+}
+
+// CommandName implements Command
+func (c *LintBlueprint) CommandName() string { return "lint-blueprint" }
+
+// Kinds of problems reported when linting a blueprint.
+const (
+	LintSyntaxError          = "syntax-error"
+	LintUnknownFunction      = "unknown-function"
+	LintMissingTemplate      = "missing-template"
+	LintUnusedTemplate       = "unused-template"
+	LintUndeclaredVariable   = "undeclared-variable"
+	LintDuplicateDestination = "duplicate-destination"
+	LintDestinationOutside   = "destination-outside-project"
+)
+
+// LintProblem describes a single problem found in a blueprint.
+type LintProblem struct {
+	File    string // The file containing the problem
+	Line    int    // The line on which the problem occurs, 0 if unknown
+	Kind    string // One of the Lint* constants
+	Message string
+}
+
+// String formats the problem as file:line: message
+func (p *LintProblem) String() string {
+	if p.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Message)
+	}
+	return fmt.Sprintf("%s: %s", p.File, p.Message)
+}
+
+// LintBlueprintInFileSystem parses all templates, skeleton files and
+// destination file names of a blueprint without rendering them and
+// emits a "blueprint-lint-problem" event for every problem found.
+//
+// After all checks have run, a "blueprint-linted" event carrying the
+// number of problems is emitted.
+type LintBlueprintInFileSystem struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+}
+
+// NewLintBlueprintInFileSystem returns a new command handler with the given file system and store.
+func NewLintBlueprintInFileSystem(fs FileSystem, store Store, events EventStore) *LintBlueprintInFileSystem {
+	return &LintBlueprintInFileSystem{
+		fs:     fs,
+		store:  store,
+		events: events,
+	}
+}
+
+// blueprintLinter collects the problems found in a single blueprint.
+type blueprintLinter struct {
+	blueprint      *Blueprint
+	definitionFile string
+	definition     []string
+	templateDir    string
+	templates      map[string]string
+	skeletonDir    string
+	skeleton       map[string]string // skeleton files that are rendered, indexed by their path relative to skeletonDir
+	funcs          template.FuncMap
+	problems       []*LintProblem
+	reported       map[string]bool
+	allowed        map[string]bool // additional variables provided to the template being parsed
+}
+
+// Execute implements CommandHandler
+func (h *LintBlueprintInFileSystem) Execute(command Command) error {
+	args := command.(*LintBlueprint)
+	blueprint := new(Blueprint)
+	if err := h.store.Get(args.BlueprintName, blueprint); err != nil {
+		return err
+	}
+	templates, err := readTemplates(h.fs, args.BlueprintName)
+	if err != nil {
+		return err
+	}
+
+	skeletonDir := BlueprintSkeletonDirectory(args.BlueprintName)
+	skeleton, err := readSkeleton(h.fs, skeletonDir)
+	if err != nil {
+		return err
+	}
+
+	templateDir := BlueprintTemplateDirectory(args.BlueprintName)
+	linter := &blueprintLinter{
+		blueprint:      blueprint,
+		definitionFile: filepath.Join("blueprints", args.BlueprintName),
+		templateDir:    templateDir,
+		templates:      templates,
+		skeletonDir:    skeletonDir,
+		skeleton:       skeleton,
+		funcs:          template.FuncMap(NewHTMLTemplateEngine(templateDir, h.fs).TemplateFuncs()),
+		reported:       map[string]bool{},
+	}
+	h.loadDefinition(linter, args.BlueprintName)
+	linter.lint()
+
+	for _, problem := range linter.problems {
+		h.events.Emit(&Event{
+			Name: "blueprint-lint-problem",
+			Payload: EventPayload{
+				"blueprintName": blueprint.Name,
+				"file":          problem.File,
+				"line":          problem.Line,
+				"kind":          problem.Kind,
+				"message":       problem.Message,
+				"problem":       problem,
+			},
+		})
+	}
+	h.events.Emit(&Event{
+		Name: "blueprint-linted",
+		Payload: EventPayload{
+			"name":     blueprint.Name,
+			"problems": len(linter.problems),
+		},
+	})
+	return nil
+}
+
+// readSkeleton returns the skeleton files below dir that are rendered
+// when rendering the blueprint, leaving out the ignore file and the
+// files matched by it.
+func readSkeleton(fs FileSystem, dir string) (map[string]string, error) {
+	files, err := readTree(fs, dir)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := readIgnorePatterns(fs, filepath.Join(dir, SkeletonIgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	for relative := range files {
+		segments := strings.Split(filepath.ToSlash(relative), "/")
+		for i := range segments {
+			prefix := filepath.FromSlash(strings.Join(segments[:i+1], "/"))
+			if prefix == SkeletonIgnoreFile || isIgnored(patterns, prefix) {
+				delete(files, relative)
+				break
+			}
+		}
+	}
+	return files, nil
+}
+
+// loadDefinition reads the file in which the blueprint is stored, if
+// the store provides access to it, so that problems can be reported
+// with line numbers.
+func (h *LintBlueprintInFileSystem) loadDefinition(linter *blueprintLinter, name string) {
+	locator, ok := h.store.(interface {
+		Filename(id string) (string, error)
+	})
+	if !ok {
+		return
+	}
+	filename, err := locator.Filename(name)
+	if err != nil {
+		return
+	}
+	linter.definitionFile = filename
+	f, err := h.fs.Open(filename)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	contents, err := ioutil.ReadAll(f)
+	if err != nil {
+		return
+	}
+	linter.definition = strings.Split(string(contents), "\n")
+}
+
+// lint runs all checks.  Templates, destination file names, skeleton
+// files and their paths are parsed, but not rendered.
+func (l *blueprintLinter) lint() {
+	used := map[string]bool{}
+	defined := map[string]bool{}
+	for _, name := range sortedKeys(l.templates) {
+		if l.verbatim(name) {
+			continue
+		}
+		tmpl := l.parse(filepath.Join(l.templateDir, name), name, l.templates[name], 0)
+		if tmpl == nil {
+			continue
+		}
+		for _, t := range tmpl.Templates() {
+			defined[t.Name()] = true
+			if t.Tree == nil {
+				continue
+			}
+			l.walk(t.Tree.Root, true, func(kind, ref string, node parse.Node) {
+				if kind == "template" {
+					used[ref] = true
+				}
+			})
+		}
+	}
+
+	destinations := []string{}
+	for destination := range l.blueprint.Files {
+		destinations = append(destinations, destination)
+	}
+	sort.Strings(destinations)
+
+	rendered := map[string]string{}
+	for _, destination := range destinations {
+		templateName := l.blueprint.Files[destination]
+		line := l.definitionLine(destination)
+		used[templateName] = true
+		if _, found := l.templates[templateName]; !found && !defined[templateName] {
+			l.report(l.definitionFile, line, LintMissingTemplate,
+				fmt.Sprintf("template %q used for %q does not exist", templateName, destination))
+		}
+
+		if condition := l.blueprint.FileCondition(destination); condition != "" {
+			l.parse(l.definitionFile, "condition of "+destination, condition, line)
+		}
+		if each := l.blueprint.FileIteration(destination); each != "" {
+			if variable := strings.Split(each, ".")[0]; l.blueprint.Argument(variable) == nil {
+				l.report(l.definitionFile, l.definitionLine(each), LintUndeclaredVariable,
+					fmt.Sprintf("variable %q iterated over for %q is not declared as an argument", variable, destination))
+			}
+		}
+
+		tmpl := l.parse(l.definitionFile, destination, destination, line)
+		if tmpl == nil {
+			continue
+		}
+		path := l.renderDestination(tmpl)
+		if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			l.report(l.definitionFile, line, LintDestinationOutside,
+				fmt.Sprintf("destination %q resolves to %q outside of the project", destination, path))
+		}
+		if other, found := rendered[path]; found {
+			l.report(l.definitionFile, line, LintDuplicateDestination,
+				fmt.Sprintf("destinations %q and %q both resolve to %q", other, destination, path))
+		} else {
+			rendered[path] = destination
+		}
+	}
+
+	for _, relative := range sortedKeys(l.skeleton) {
+		file := filepath.Join(l.skeletonDir, relative)
+		for _, segment := range strings.Split(filepath.ToSlash(relative), "/") {
+			l.parse(file, "path of "+relative, segment, 0)
+		}
+		if contents := l.skeleton[relative]; !isBinary([]byte(contents)) {
+			l.parse(file, relative, contents, 0)
+		}
+	}
+
+	l.allowed = map[string]bool{"files": true}
+	for i, hook := range l.blueprint.PostInstall {
+		l.parse(l.definitionFile, fmt.Sprintf("post install hook %d", i+1), hook, l.definitionLine(hook))
+	}
+	l.allowed = nil
+
+	for _, name := range sortedKeys(l.templates) {
+		if !used[name] {
+			l.report(filepath.Join(l.templateDir, name), 0, LintUnusedTemplate,
+				fmt.Sprintf("template %q is not used by any file", name))
+		}
+	}
+}
+
+// verbatim reports whether the template called name is copied instead
+// of being rendered, because it is binary or only used by files
+// marked with FileActionCopy.
+func (l *blueprintLinter) verbatim(name string) bool {
+	if isBinary([]byte(l.templates[name])) {
+		return true
+	}
+	copied := false
+	for destination, templateName := range l.blueprint.Files {
+		if templateName != name {
+			continue
+		}
+		if l.blueprint.FileAction(destination) != FileActionCopy {
+			return false
+		}
+		copied = true
+	}
+	return copied
+}
+
+// sortedKeys returns the keys of m in lexical order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// templateErrorLine extracts the line number from errors reported by text/template.
+var templateErrorLine = regexp.MustCompile(`^template: [^:]*:(\d+):`)
+
+// unknownFunction extracts the function name from errors about undefined functions.
+var unknownFunction = regexp.MustCompile(`function "([^"]+)" not defined`)
+
+// parse parses text as a template, reporting syntax errors and
+// undeclared variables.  For templates embedded in the blueprint
+// definition, baseLine is the line at which the template appears.
+//
+// If the template cannot be parsed, nil is returned.
+func (l *blueprintLinter) parse(file, name, text string, baseLine int) *template.Template {
+	tmpl, err := template.New(name).Funcs(l.funcs).Parse(text)
+	if err != nil {
+		line := baseLine
+		if match := templateErrorLine.FindStringSubmatch(err.Error()); match != nil && baseLine == 0 {
+			line, _ = strconv.Atoi(match[1])
+		}
+		kind := LintSyntaxError
+		if unknownFunction.MatchString(err.Error()) {
+			kind = LintUnknownFunction
+		}
+		l.report(file, line, kind, err.Error())
+		return nil
+	}
+
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		tree := t.Tree
+		l.walk(tree.Root, true, func(kind, variable string, node parse.Node) {
+			if kind != "variable" || l.declared(variable) {
+				return
+			}
+			line := baseLine
+			if baseLine == 0 {
+				line = nodeLine(tree, node)
+			}
+			l.report(file, line, LintUndeclaredVariable,
+				fmt.Sprintf("variable %q is not declared as an argument", variable))
+		})
+	}
+	return tmpl
+}
+
+// declared reports whether variable is provided when rendering the
+// blueprint, either as an argument, as the element of an iteration or
+// as one of the variables allowed for the template being parsed.
+func (l *blueprintLinter) declared(variable string) bool {
+	if l.blueprint.Argument(variable) != nil || l.allowed[variable] {
+		return true
+	}
+	return len(l.blueprint.Iterations) > 0 && (variable == "item" || variable == "index")
+}
+
+// nodeLine returns the line on which node appears in tree.
+func nodeLine(tree *parse.Tree, node parse.Node) int {
+	location, _ := tree.ErrorContext(node)
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return line
+}
+
+// walk visits node and its children, calling visit for every
+// reference to a variable of the template context and for every
+// template invocation.  rootDot is true as long as dot refers to the
+// template context.
+func (l *blueprintLinter) walk(node parse.Node, rootDot bool, visit func(kind, name string, node parse.Node)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			l.walk(child, rootDot, visit)
+		}
+	case *parse.ActionNode:
+		l.walk(n.Pipe, rootDot, visit)
+	case *parse.IfNode:
+		l.walk(n.Pipe, rootDot, visit)
+		l.walk(n.List, rootDot, visit)
+		l.walk(n.ElseList, rootDot, visit)
+	case *parse.RangeNode:
+		l.walk(n.Pipe, rootDot, visit)
+		l.walk(n.List, false, visit)
+		l.walk(n.ElseList, rootDot, visit)
+	case *parse.WithNode:
+		l.walk(n.Pipe, rootDot, visit)
+		l.walk(n.List, false, visit)
+		l.walk(n.ElseList, rootDot, visit)
+	case *parse.TemplateNode:
+		visit("template", n.Name, n)
+		l.walk(n.Pipe, rootDot, visit)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			l.walk(cmd, rootDot, visit)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			l.walk(arg, rootDot, visit)
+		}
+	case *parse.ChainNode:
+		l.walk(n.Node, rootDot, visit)
+	case *parse.FieldNode:
+		if rootDot {
+			visit("variable", n.Ident[0], n)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			visit("variable", n.Ident[1], n)
+		}
+	}
+}
+
+// renderDestination renders a destination file name using sample
+// values for all arguments and returns the cleaned result.
+func (l *blueprintLinter) renderDestination(tmpl *template.Template) string {
+	data := map[string]interface{}{}
+	for _, arg := range l.blueprint.Arguments {
+		data[arg.Name] = arg.Name
+		if arg.Default != "" {
+			data[arg.Name] = arg.Default
+		}
+	}
+	out := new(strings.Builder)
+	if err := tmpl.Execute(out, data); err != nil {
+		return tmpl.Name()
+	}
+	return filepath.Clean(out.String())
+}
+
+// definitionLine returns the line of the blueprint definition on which text appears, or 0 if it cannot be found.
+func (l *blueprintLinter) definitionLine(text string) int {
+	candidates := []string{text}
+	if encoded, err := json.Marshal(text); err == nil {
+		candidates = append(candidates, strings.Trim(string(encoded), `"`))
+	}
+	for i, line := range l.definition {
+		for _, candidate := range candidates {
+			if strings.Contains(line, candidate) {
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
+
+// report records a problem, ignoring duplicates.
+func (l *blueprintLinter) report(file string, line int, kind, message string) {
+	problem := &LintProblem{File: file, Line: line, Kind: kind, Message: message}
+	if l.reported[problem.String()] {
+		return
+	}
+	l.reported[problem.String()] = true
+	l.problems = append(l.problems, problem)
+}
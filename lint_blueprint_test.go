@@ -0,0 +1,157 @@
+package dux_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+// lintProblems lints the named blueprint and returns the problems found, indexed by kind.
+func lintProblems(t *testing.T, app *dux.Application, name string) map[string][]*dux.LintProblem {
+	t.Helper()
+	problems := map[string][]*dux.LintProblem{}
+	done := app.EventStore.Subscribe(func(e *dux.Event) {
+		if e.Name != "blueprint-lint-problem" {
+			return
+		}
+		problem := e.Payload["problem"].(*dux.LintProblem)
+		problems[problem.Kind] = append(problems[problem.Kind], problem)
+	})
+	defer done()
+	h.FailOnExecuteError(t, app)(&dux.LintBlueprint{BlueprintName: name})
+	return problems
+}
+
+func defineArgument(name string) *dux.DefineBlueprintArgument {
+	return &dux.DefineBlueprintArgument{BlueprintName: "a", Argument: &dux.Argument{Name: name}}
+}
+
+func TestLintBlueprint_reports_no_problems_for_valid_blueprints(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(defineArgument("name"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{range .name}}{{.}}{{end}}"))
+	do(h.DefineBlueprintFile("a", "{{(identifier .name).ToSnake.Lower}}.go", "x.tmpl"))
+
+	if problems := lintProblems(t, app, "a"); len(problems) != 0 {
+		t.Fatalf("Expected no problems, got %v", problems)
+	}
+	h.AssertEvent(t, app.EventStore, "blueprint-linted", dux.EventPayload{"name": "a", "problems": 0})
+}
+
+func TestLintBlueprint_reports_missing_and_unused_templates(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "unused.tmpl", "unused"))
+	do(h.DefineBlueprintFile("a", "out", "missing.tmpl"))
+
+	problems := lintProblems(t, app, "a")
+	if len(problems[dux.LintMissingTemplate]) != 1 {
+		t.Fatalf("Expected one missing template, got %v", problems)
+	}
+	if got, want := problems[dux.LintMissingTemplate][0].Line, 1; got != want {
+		t.Fatalf("Expected missing template to be reported on line %d, got %d", want, got)
+	}
+	if len(problems[dux.LintUnusedTemplate]) != 1 {
+		t.Fatalf("Expected one unused template, got %v", problems)
+	}
+}
+
+func TestLintBlueprint_reports_undeclared_variables_and_unknown_functions_with_line(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(defineArgument("name"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.name}}\n{{.other}}\n"))
+	do(h.DefineBlueprintTemplate("a", "y.tmpl", "\n{{frobnicate .name}}"))
+	do(h.DefineBlueprintFile("a", "x", "x.tmpl"))
+	do(h.DefineBlueprintFile("a", "y", "y.tmpl"))
+
+	problems := lintProblems(t, app, "a")
+	undeclared := problems[dux.LintUndeclaredVariable]
+	if len(undeclared) != 1 || undeclared[0].Line != 2 || undeclared[0].File != "blueprints/a/templates/x.tmpl" {
+		t.Fatalf("Expected undeclared variable on line 2 of x.tmpl, got %v", undeclared)
+	}
+	unknown := problems[dux.LintUnknownFunction]
+	if len(unknown) != 1 || unknown[0].Line != 2 {
+		t.Fatalf("Expected unknown function on line 2, got %v", unknown)
+	}
+}
+
+func TestLintBlueprint_reports_duplicate_and_escaping_destinations(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(defineArgument("name"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "x"))
+	do(h.DefineBlueprintFile("a", "{{.name}}.go", "x.tmpl"))
+	do(h.DefineBlueprintFile("a", "./{{.name}}.go", "x.tmpl"))
+	do(h.DefineBlueprintFile("a", "../../etc/{{.name}}", "x.tmpl"))
+
+	problems := lintProblems(t, app, "a")
+	if len(problems[dux.LintDuplicateDestination]) != 1 {
+		t.Fatalf("Expected one duplicate destination, got %v", problems)
+	}
+	if len(problems[dux.LintDestinationOutside]) != 1 {
+		t.Fatalf("Expected one destination outside of the project, got %v", problems)
+	}
+}
+
+func TestLintBlueprint_accepts_item_and_index_in_iterated_files(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(defineArgument("fields"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.index}} {{.item}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{.item}}.sql", TemplateName: "x.tmpl", Each: "fields", Condition: "{{.with_tests"})
+
+	problems := lintProblems(t, app, "a")
+	if len(problems[dux.LintUndeclaredVariable]) != 0 {
+		t.Fatalf("Expected no undeclared variables, got %v", problems)
+	}
+	if len(problems[dux.LintSyntaxError]) != 1 {
+		t.Fatalf("Expected a syntax error in the condition, got %v", problems)
+	}
+}
+
+func TestLintBlueprint_checks_skeleton_files_and_their_paths(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(defineArgument("name"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{
+		"{{.name}}/{{.other}}.txt": "{{.name}}",
+		"README.tmpl":              "About\n{{.name",
+		"ignored/{{.unknown}}":     "{{.unknown}}",
+		dux.SkeletonIgnoreFile:     "ignored\n",
+	})
+
+	problems := lintProblems(t, app, "a")
+	undeclared := problems[dux.LintUndeclaredVariable]
+	if len(undeclared) != 1 || undeclared[0].File != "blueprints/a/skeleton/{{.name}}/{{.other}}.txt" {
+		t.Fatalf("Expected undeclared variable in the skeleton path, got %v", undeclared)
+	}
+	syntax := problems[dux.LintSyntaxError]
+	if len(syntax) != 1 || syntax[0].File != "blueprints/a/skeleton/README.tmpl" || syntax[0].Line != 2 {
+		t.Fatalf("Expected syntax error on line 2 of README.tmpl, got %v", syntax)
+	}
+}
+
+func TestLintBlueprint_reports_iterations_over_undeclared_arguments(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(defineArgument("fields"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.item}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{.item}}.sql", TemplateName: "x.tmpl", Each: "fields"})
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{.item}}.go", TemplateName: "x.tmpl", Each: "columns.names"})
+
+	undeclared := lintProblems(t, app, "a")[dux.LintUndeclaredVariable]
+	if len(undeclared) != 1 || !strings.Contains(undeclared[0].Message, `"columns"`) {
+		t.Fatalf("Expected iteration over undeclared argument columns, got %v", undeclared)
+	}
+}
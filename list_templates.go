@@ -1,7 +1,5 @@
 package dux
 
-import "path/filepath"
-
 // ListTemplates creates a new blueprint with the given name.
 type ListTemplates struct {
 	BlueprintName string
@@ -27,7 +25,7 @@ func NewListTemplatesInFileSystem(fs FileSystem, events EventStore) *ListTemplat
 // Execute implements CommandHandler.
 func (h *ListTemplatesInFileSystem) Execute(command Command) error {
 	args := command.(*ListTemplates)
-	templateDir := filepath.Join("blueprints", args.BlueprintName, "templates")
+	templateDir := BlueprintTemplateDirectory(args.BlueprintName)
 	names, err := h.fs.List(templateDir)
 	if err != nil {
 		return err
@@ -0,0 +1,78 @@
+package dux
+
+import "fmt"
+
+// MigrateBlueprints rewrites all stored blueprints in the latest schema version.
+type MigrateBlueprints struct{}
+
+// CommandName implements Command
+func (c *MigrateBlueprints) CommandName() string { return "migrate-blueprints" }
+
+// MigrationFailedError is returned when one or more blueprints could
+// not be migrated.
+type MigrationFailedError struct {
+	Errors []error // The errors of all failed blueprints, prefixed with the blueprint's name
+}
+
+// Error implements the error interface
+func (err *MigrationFailedError) Error() string {
+	return fmt.Sprintf("Failed to migrate %d blueprint(s):\n%s", len(err.Errors), IndentErrors(err.Errors))
+}
+
+// MigrateBlueprintsInStore loads every blueprint from the store,
+// which migrates it to the latest schema version, and stores it
+// again.
+//
+// Blueprints that cannot be migrated do not stop the others from being
+// migrated.  Their errors are emitted as events and returned as a
+// *MigrationFailedError once all blueprints have been processed.
+type MigrateBlueprintsInStore struct {
+	store  Store
+	events EventStore
+}
+
+// NewMigrateBlueprintsInStore returns a new command handler with the given store
+func NewMigrateBlueprintsInStore(store Store, events EventStore) *MigrateBlueprintsInStore {
+	return &MigrateBlueprintsInStore{
+		store:  store,
+		events: events,
+	}
+}
+
+// Execute implements CommandHandler
+func (h *MigrateBlueprintsInStore) Execute(command Command) error {
+	names, err := h.store.List("*")
+	if err != nil {
+		return err
+	}
+
+	failures := []error{}
+	for _, name := range names {
+		blueprint := new(Blueprint)
+		err := h.store.Get(name, blueprint)
+		if err == nil {
+			err = h.store.Put(name, blueprint)
+		}
+		if err != nil {
+			h.events.Emit(&Event{
+				Name:    "blueprint-migration-failed",
+				Error:   err,
+				Payload: EventPayload{"name": name},
+			})
+			failures = append(failures, fmt.Errorf("%s: %s", name, err))
+			continue
+		}
+		h.events.Emit(&Event{
+			Name: "blueprint-migrated",
+			Payload: EventPayload{
+				"name":          name,
+				"schemaVersion": LatestBlueprintSchemaVersion,
+			},
+		})
+	}
+
+	if len(failures) > 0 {
+		return &MigrationFailedError{Errors: failures}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package dux
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SchemaVersionKey is the name of the field holding the schema
+// version of a stored document.
+const SchemaVersionKey = "schemaVersion"
+
+// Migration upgrades a decoded document from one schema version to
+// the next by modifying it in place.
+type Migration func(document map[string]interface{}) error
+
+// BlueprintMigrations is the chain of migrations applied to stored
+// blueprints.  The migration at index i upgrades a blueprint from
+// schema version i to schema version i+1, which makes the length of
+// this list the latest blueprint schema version.
+var BlueprintMigrations = []Migration{
+	migrateBlueprintV0ToV1,
+}
+
+// LatestBlueprintSchemaVersion is the schema version written for blueprints.
+var LatestBlueprintSchemaVersion = len(BlueprintMigrations)
+
+// migrateBlueprintV0ToV1 upgrades blueprints created before the
+// introduction of schema versions by making sure that the list of
+// files is never null.
+func migrateBlueprintV0ToV1(document map[string]interface{}) error {
+	key := documentKey(document, "Files")
+	if document[key] == nil {
+		document[key] = map[string]interface{}{}
+	}
+	return nil
+}
+
+// documentKey returns the key in document matching name
+// case-insensitively, mirroring how encoding/json matches field
+// names.  If no key matches, name is returned.
+func documentKey(document map[string]interface{}, name string) string {
+	if _, found := document[name]; found {
+		return name
+	}
+	for key := range document {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+// SchemaVersionError is returned when a document cannot be migrated
+// because its schema version is not supported.
+type SchemaVersionError struct {
+	Version int
+	Latest  int
+}
+
+// Error implements the error interface
+func (err *SchemaVersionError) Error() string {
+	return fmt.Sprintf("unsupported schema version %d (latest is %d)", err.Version, err.Latest)
+}
+
+// schemaVersionOf returns the schema version recorded in document.
+// Documents without a schema version are considered to be at
+// version 0.
+func schemaVersionOf(document map[string]interface{}) (int, error) {
+	switch version := document[SchemaVersionKey].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return version, nil
+	case int64:
+		return int(version), nil
+	case float64:
+		return int(version), nil
+	case json.Number:
+		v, err := version.Int64()
+		return int(v), err
+	default:
+		return 0, fmt.Errorf("invalid %s: %v", SchemaVersionKey, version)
+	}
+}
+
+// Migrate applies all migrations in migrations to document, starting
+// at the schema version recorded in the document.  The schema version
+// of the migrated document is set to the length of migrations.
+func Migrate(document map[string]interface{}, migrations []Migration) error {
+	version, err := schemaVersionOf(document)
+	if err != nil {
+		return err
+	}
+	if version < 0 || version > len(migrations) {
+		return &SchemaVersionError{Version: version, Latest: len(migrations)}
+	}
+
+	for _, migration := range migrations[version:] {
+		if err := migration(document); err != nil {
+			return err
+		}
+	}
+
+	document[SchemaVersionKey] = len(migrations)
+	return nil
+}
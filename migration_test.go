@@ -0,0 +1,86 @@
+package dux_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func writeFile(t *testing.T, fs dux.FileSystem, filename, contents string) {
+	t.Helper()
+	f, err := fs.Create(filename)
+	if err != nil {
+		t.Fatalf("Create %q: %s", filename, err)
+	}
+	defer f.Close()
+	fmt.Fprint(f, contents)
+}
+
+func TestFileSystemStore_Get_migrates_blueprints_without_schema_version(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.json", `{"Name":"a","Files":null}`)
+
+	blueprint := new(dux.Blueprint)
+	if err := app.Store.Get("a", blueprint); err != nil {
+		t.Fatalf("Store.Get: %s", err)
+	}
+	if got, want := blueprint.SchemaVersion, dux.LatestBlueprintSchemaVersion; got != want {
+		t.Fatalf("Expected schema version %d, got %d", want, got)
+	}
+	if blueprint.Files == nil {
+		t.Fatalf("Expected Files to be initialized by migration")
+	}
+}
+
+func TestFileSystemStore_Get_rejects_blueprints_from_the_future(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.json", `{"Name":"a","schemaVersion":1000}`)
+
+	err := app.Store.Get("a", new(dux.Blueprint))
+	if err == nil || !strings.Contains(err.Error(), "unsupported schema version 1000") {
+		t.Fatalf("Expected unsupported schema version error, got %v", err)
+	}
+}
+
+func TestMigrateBlueprintsInStore_rewrites_blueprints_in_latest_format(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	writeFile(t, app.FileSystem, "blueprints/a.json", `{"Name":"a"}`)
+	do(h.MigrateBlueprints())
+
+	h.AssertEvent(t, app.EventStore, "blueprint-migrated", dux.EventPayload{
+		"name":          "a",
+		"schemaVersion": dux.LatestBlueprintSchemaVersion,
+	})
+	f, err := app.FileSystem.Open("blueprints/a.json")
+	if err != nil {
+		t.Fatalf("Open: %s", err)
+	}
+	defer f.Close()
+	contents, _ := ioutil.ReadAll(f)
+	expected := fmt.Sprintf(`"schemaVersion":%d`, dux.LatestBlueprintSchemaVersion)
+	if !strings.Contains(string(contents), expected) {
+		t.Fatalf("Expected %s to contain %s", contents, expected)
+	}
+}
+
+func TestMigrateBlueprintsInStore_returns_the_errors_of_all_failed_blueprints(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.json", `{"Name":"a","schemaVersion":1000}`)
+	writeFile(t, app.FileSystem, "blueprints/b.json", `{"Name":"b"}`)
+
+	err := app.Execute(h.MigrateBlueprints())
+
+	migrationErr, ok := err.(*dux.MigrationFailedError)
+	if !ok {
+		t.Fatalf("Expected *dux.MigrationFailedError, got %#v", err)
+	}
+	if len(migrationErr.Errors) != 1 || !strings.HasPrefix(migrationErr.Errors[0].Error(), "a: ") {
+		t.Fatalf("Expected one error for blueprint a, got %v", migrationErr.Errors)
+	}
+	h.AssertEvent(t, app.EventStore, "blueprint-migrated", dux.EventPayload{"name": "b"})
+}
@@ -1,25 +1,146 @@
 package dux
 
 import (
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// ErrOutsideRoot is wrapped by the errors returned from an
+// OnDiskFileSystem when a path resolves to a location outside of the
+// file system's root directory.
+var ErrOutsideRoot = errors.New("path escapes file system root")
+
 // OnDiskFileSystem implements FileSystem with files from the Operating System.
+//
+// All paths are interpreted relative to the root directory of the
+// file system.  Paths that resolve to a location outside of the root
+// directory, either lexically or by following symbolic links, are
+// rejected with an error wrapping ErrOutsideRoot.
 type OnDiskFileSystem struct {
+	root string
 }
 
 // NewOnDiskFileSystem creates a new OnDiskFileSystem anchored at the
-// process's current directory.
-func NewOnDiskFileSystem() *OnDiskFileSystem {
-	return &OnDiskFileSystem{}
+// given root directory.
+func NewOnDiskFileSystem(root string) *OnDiskFileSystem {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	return &OnDiskFileSystem{root: root}
+}
+
+// Root returns the absolute path of the directory at which this file system is anchored.
+func (fs *OnDiskFileSystem) Root() string {
+	return fs.root
+}
+
+// resolve maps filename to a path on disk, ensuring that the result
+// is located within the root directory.
+//
+// Relative paths are interpreted relative to the root directory.
+// Symbolic links in the path are followed to check that they do not
+// point outside of the root directory, even if their target does not
+// exist yet.
+func (fs *OnDiskFileSystem) resolve(op, filename string) (string, error) {
+	path := filename
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(fs.root, path)
+	}
+	path = filepath.Clean(path)
+	if !fs.contains(path) {
+		return "", NewFileSystemError(op, filename, ErrOutsideRoot)
+	}
+	if err := fs.checkLinks(path); err != nil {
+		return "", NewFileSystemError(op, filename, err)
+	}
+
+	return path, nil
+}
+
+// maxLinks is the number of symbolic links resolve follows before
+// giving up.
+const maxLinks = 255
+
+// checkLinks follows the symbolic links in path, which must be located
+// within the root directory, one component at a time and returns
+// ErrOutsideRoot if any of them points outside of the root directory.
+//
+// Dangling links are checked as well, because creating a file through
+// them would create their target.
+func (fs *OnDiskFileSystem) checkLinks(path string) error {
+	pending := fs.components(path)
+	current := fs.root
+	for links := 0; len(pending) > 0; {
+		next := filepath.Join(current, pending[0])
+		pending = pending[1:]
+		info, err := os.Lstat(next)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		if links++; links > maxLinks {
+			return errors.New("too many levels of symbolic links")
+		}
+		target, err := os.Readlink(next)
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(current, target)
+		}
+		target = filepath.Clean(target)
+		if !fs.contains(target) {
+			return ErrOutsideRoot
+		}
+		pending = append(fs.components(target), pending...)
+		current = fs.root
+	}
+	return nil
+}
+
+// components splits path, which must be located within the root
+// directory, into its components relative to the root directory.
+func (fs *OnDiskFileSystem) components(path string) []string {
+	relative, err := filepath.Rel(fs.root, path)
+	if err != nil || relative == "." {
+		return nil
+	}
+	return strings.Split(relative, string(filepath.Separator))
+}
+
+// contains returns true if path is the root directory or located below it.
+func (fs *OnDiskFileSystem) contains(path string) bool {
+	relative, err := filepath.Rel(fs.root, path)
+	if err != nil {
+		return false
+	}
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
 }
 
 // Open implements FileSystem by delegating to os.Open
 func (fs *OnDiskFileSystem) Open(filename string) (io.ReadCloser, error) {
-	f, err := os.Open(filename)
-	return f, err
+	path, err := fs.resolve("open", filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, NewFileSystemError("open", filename, err)
+	}
+	return f, nil
 }
 
 // Create implements FileSystem by delegating to os.Create.
@@ -29,22 +150,39 @@ func (fs *OnDiskFileSystem) Open(filename string) (io.ReadCloser, error) {
 // Directories are created with permissions 0755 and files are created
 // with permissions 0644.
 func (fs *OnDiskFileSystem) Create(filename string) (io.WriteCloser, error) {
-	os.MkdirAll(filepath.Dir(filename), 0755)
-	f, err := os.Create(filename)
-	return f, err
+	path, err := fs.resolve("create", filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), DefaultDirMode); err != nil {
+		return nil, NewFileSystemError("create", filename, err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, DefaultFileMode)
+	if err != nil {
+		return nil, NewFileSystemError("create", filename, err)
+	}
+	return f, nil
 }
 
 // List returns the names of all files in the given directory.
 //
 // Unlike the command `ls`, no entries for "." and ".." are returned.
 func (fs *OnDiskFileSystem) List(dir string) ([]string, error) {
-	f, err := os.Open(dir)
+	path, err := fs.resolve("list", dir)
 	if err != nil {
 		return []string{}, err
 	}
+	f, err := os.Open(path)
+	if err != nil {
+		return []string{}, NewFileSystemError("list", dir, err)
+	}
+	defer f.Close()
 
 	names, err := f.Readdirnames(0)
-	return names, err
+	if err != nil {
+		return names, NewFileSystemError("list", dir, err)
+	}
+	return names, nil
 }
 
 // Rename renames a file using os.Rename.
@@ -52,6 +190,95 @@ func (fs *OnDiskFileSystem) List(dir string) ([]string, error) {
 // Target directories are created using os.MkdirAll before renaming
 // the file.
 func (fs *OnDiskFileSystem) Rename(oldpath, newpath string) error {
-	os.MkdirAll(filepath.Dir(newpath), 0755)
-	return os.Rename(oldpath, newpath)
+	from, err := fs.resolve("rename", oldpath)
+	if err != nil {
+		return err
+	}
+	to, err := fs.resolve("rename", newpath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(to), DefaultDirMode); err != nil {
+		return NewFileSystemError("rename", newpath, err)
+	}
+	if err := os.Rename(from, to); err != nil {
+		return NewFileSystemError("rename", oldpath, err)
+	}
+	return nil
+}
+
+// Stat implements FileSystem by delegating to os.Stat
+func (fs *OnDiskFileSystem) Stat(filename string) (os.FileInfo, error) {
+	path, err := fs.resolve("stat", filename)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, NewFileSystemError("stat", filename, err)
+	}
+	return info, nil
+}
+
+// Exists implements FileSystem by checking the error returned by os.Stat
+func (fs *OnDiskFileSystem) Exists(filename string) (bool, error) {
+	path, err := fs.resolve("exists", filename)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return false, NewFileSystemError("exists", filename, err)
+}
+
+// Remove implements FileSystem by delegating to os.Remove
+func (fs *OnDiskFileSystem) Remove(filename string) error {
+	path, err := fs.resolve("remove", filename)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return NewFileSystemError("remove", filename, err)
+	}
+	return nil
+}
+
+// Chmod implements FileSystem by delegating to os.Chmod
+func (fs *OnDiskFileSystem) Chmod(filename string, mode os.FileMode) error {
+	path, err := fs.resolve("chmod", filename)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		return NewFileSystemError("chmod", filename, err)
+	}
+	return nil
+}
+
+// Walk implements FileSystem by delegating to filepath.Walk
+//
+// The paths passed to walkFn are relative to root in the same way
+// as root is relative to the root of the file system.  Errors passed
+// to walkFn are wrapped in a *FileSystemError.
+func (fs *OnDiskFileSystem) Walk(root string, walkFn filepath.WalkFunc) error {
+	resolvedRoot, err := fs.resolve("walk", root)
+	if err != nil {
+		return walkFn(root, nil, err)
+	}
+	return filepath.Walk(resolvedRoot, func(path string, info os.FileInfo, err error) error {
+		relative, relErr := filepath.Rel(resolvedRoot, path)
+		if relErr != nil {
+			return NewFileSystemError("walk", path, relErr)
+		}
+		path = filepath.Join(root, relative)
+		if err != nil {
+			err = NewFileSystemError("walk", path, err)
+		}
+		return walkFn(path, info, err)
+	})
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/dhamidi/dux"
@@ -12,7 +13,7 @@ import (
 func TestOnDiskFileSystem_CreateAndOpen(t *testing.T) {
 	os.RemoveAll("a")
 	defer os.RemoveAll("a")
-	fs := dux.NewOnDiskFileSystem()
+	fs := dux.NewOnDiskFileSystem(".")
 	f, err := fs.Create("a/b/c")
 	if err != nil {
 		t.Fatalf("fs.Create: %s", err)
@@ -37,7 +38,7 @@ func TestOnDiskFileSystem_CreateAndOpen(t *testing.T) {
 }
 
 func TestOnDiskFileSystem_List_does_not_return_dot_nor_dotdot(t *testing.T) {
-	fs := dux.NewOnDiskFileSystem()
+	fs := dux.NewOnDiskFileSystem(".")
 	names, err := fs.List(".")
 	if err != nil {
 		t.Fatalf("fs.List: %s", err)
@@ -49,3 +50,165 @@ func TestOnDiskFileSystem_List_does_not_return_dot_nor_dotdot(t *testing.T) {
 		}
 	}
 }
+
+func TestOnDiskFileSystem_Create_uses_default_permissions_and_Chmod_changes_them(t *testing.T) {
+	os.RemoveAll("a")
+	defer os.RemoveAll("a")
+	fs := dux.NewOnDiskFileSystem(".")
+	f, err := fs.Create("a/script")
+	if err != nil {
+		t.Fatalf("fs.Create: %s", err)
+	}
+	f.Close()
+
+	if err := fs.Chmod("a/script", dux.ExecutableFileMode); err != nil {
+		t.Fatalf("fs.Chmod: %s", err)
+	}
+	info, err := fs.Stat("a/script")
+	if err != nil {
+		t.Fatalf("fs.Stat: %s", err)
+	}
+	if got, want := info.Mode().Perm(), dux.ExecutableFileMode; got != want {
+		t.Fatalf("Expected mode %s, got %s", want, got)
+	}
+}
+
+func TestOnDiskFileSystem_wraps_errors_in_FileSystemError(t *testing.T) {
+	fs := dux.NewOnDiskFileSystem(".")
+	_, err := fs.Open("does-not-exist")
+	if _, ok := err.(*dux.FileSystemError); !ok {
+		t.Fatalf("Expected *dux.FileSystemError, got %T", err)
+	}
+	if !dux.IsNotExist(err) {
+		t.Fatalf("Expected IsNotExist(%s) to be true", err)
+	}
+	if exists, err := fs.Exists("does-not-exist"); exists || err != nil {
+		t.Fatalf("Expected Exists to return false, nil; got %v, %v", exists, err)
+	}
+	if err := fs.Remove("does-not-exist"); err == nil {
+		t.Fatalf("Expected Remove to fail")
+	} else if _, ok := err.(*dux.FileSystemError); !ok {
+		t.Fatalf("Expected *dux.FileSystemError, got %T", err)
+	}
+}
+
+func TestOnDiskFileSystem_rejects_paths_outside_of_its_root(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dux")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	fs := dux.NewOnDiskFileSystem(root)
+
+	for _, path := range []string{"../outside", "a/../../outside", filepath.Join(dir, "outside")} {
+		_, err := fs.Create(path)
+		if err == nil {
+			t.Fatalf("Expected creating %q to fail", path)
+		}
+		fsErr, ok := err.(*dux.FileSystemError)
+		if !ok || fsErr.Err != dux.ErrOutsideRoot {
+			t.Fatalf("Expected ErrOutsideRoot for %q, got %#v", path, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "outside")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no file to be created outside of root")
+	}
+}
+
+func TestOnDiskFileSystem_rejects_symlinks_pointing_outside_of_its_root(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dux")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	outside := filepath.Join(dir, "outside")
+	os.MkdirAll(root, 0755)
+	os.MkdirAll(outside, 0755)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skipf("os.Symlink: %s", err)
+	}
+
+	fs := dux.NewOnDiskFileSystem(root)
+	_, err = fs.Create("link/file")
+	fsErr, ok := err.(*dux.FileSystemError)
+	if !ok || fsErr.Err != dux.ErrOutsideRoot {
+		t.Fatalf("Expected ErrOutsideRoot, got %#v", err)
+	}
+}
+
+func TestOnDiskFileSystem_rejects_dangling_symlinks_pointing_outside_of_its_root(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dux")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	os.MkdirAll(root, 0755)
+	if err := os.Symlink("../escaped", filepath.Join(root, "link")); err != nil {
+		t.Skipf("os.Symlink: %s", err)
+	}
+
+	fs := dux.NewOnDiskFileSystem(root)
+	_, err = fs.Create("link")
+	fsErr, ok := err.(*dux.FileSystemError)
+	if !ok || fsErr.Err != dux.ErrOutsideRoot {
+		t.Fatalf("Expected ErrOutsideRoot, got %#v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "escaped")); !os.IsNotExist(err) {
+		t.Fatalf("Expected no file to be created outside of root")
+	}
+}
+
+func TestOnDiskFileSystem_follows_symlinks_within_its_root(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dux")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	root := filepath.Join(dir, "root")
+	os.MkdirAll(filepath.Join(root, "target"), 0755)
+	if err := os.Symlink("target", filepath.Join(root, "link")); err != nil {
+		t.Skipf("os.Symlink: %s", err)
+	}
+
+	fs := dux.NewOnDiskFileSystem(root)
+	f, err := fs.Create("link/file")
+	if err != nil {
+		t.Fatalf("Create: %s", err)
+	}
+	f.Close()
+	if _, err := os.Stat(filepath.Join(root, "target", "file")); err != nil {
+		t.Fatalf("Expected file to be created through link: %s", err)
+	}
+}
+
+func TestOnDiskFileSystem_interprets_paths_relative_to_its_root(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dux")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs := dux.NewOnDiskFileSystem(dir)
+	f, err := fs.Create("a/b")
+	if err != nil {
+		t.Fatalf("fs.Create: %s", err)
+	}
+	f.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "a", "b")); err != nil {
+		t.Fatalf("Expected file to be created below root: %s", err)
+	}
+
+	visited := []string{}
+	fs.Walk("a", func(path string, info os.FileInfo, err error) error {
+		visited = append(visited, path)
+		return err
+	})
+	if len(visited) != 2 || visited[1] != filepath.Join("a", "b") {
+		t.Fatalf("Expected Walk to report paths relative to root, got %v", visited)
+	}
+}
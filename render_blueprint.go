@@ -1,6 +1,12 @@
 package dux
 
-import "path/filepath"
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
 
 // RenderBlueprint is a command for rendering a given blueprint.
 type RenderBlueprint struct {
@@ -12,8 +18,40 @@ type RenderBlueprint struct {
 // CommandName implements Command
 func (cmd *RenderBlueprint) CommandName() string { return "render-blueprint" }
 
+// RenderFailedError is returned when one or more files of a blueprint
+// could not be rendered.  The files that could be rendered are left in
+// place.
+type RenderFailedError struct {
+	Blueprint string
+	Errors    []error // The errors of all failed files, in the order in which they occurred
+}
+
+// Error implements the error interface
+func (err *RenderFailedError) Error() string {
+	return fmt.Sprintf("Failed to render %d file(s) of blueprint %s:\n%s", len(err.Errors), err.Blueprint, IndentErrors(err.Errors))
+}
+
+// IndentErrors lists errs, one per line and indented by two spaces.
+func IndentErrors(errs []error) string {
+	lines := make([]string, len(errs))
+	for i, err := range errs {
+		lines[i] = "  " + err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ErrOutsideDestination is wrapped by the errors reported for
+// generated files whose name resolves to a location outside of the
+// destination directory, e.g. because it contains "..".
+var ErrOutsideDestination = errors.New("generated file is outside of the destination directory")
+
 // RenderBlueprintToFileSystem executes a RenderBlueprint command by
 // rendering the files described by the blueprint into a file system.
+//
+// A failure to render a single file does not stop the other files
+// from being rendered.  Every failure is emitted as an event and,
+// after all files have been processed, returned as part of a
+// *RenderFailedError.
 type RenderBlueprintToFileSystem struct {
 	fs     FileSystem
 	store  Store
@@ -37,44 +75,250 @@ func (r *RenderBlueprintToFileSystem) Execute(command Command) error {
 	if err := r.store.Get(args.Name, blueprint); err != nil {
 		return err
 	}
-	templates := NewHTMLTemplateEngine(filepath.Join("blueprints", blueprint.Name, "templates"), r.fs)
+	failures := []error{}
+	stopCollectingFailures := r.events.Subscribe(func(e *Event) {
+		if e.Error != nil {
+			failures = append(failures, e.Error)
+		}
+	})
+	defer stopCollectingFailures()
+
+	templates := NewHTMLTemplateEngine(BlueprintTemplateDirectory(blueprint.Name), r.fs)
 	for destinationFileName, templateName := range blueprint.Files {
-		var err error
-		outputFilePathTemplate := filepath.Join(args.Destination, destinationFileName)
-		outputFilePath, err := templates.RenderString(outputFilePathTemplate, args.Data)
+		contexts, err := fileContexts(blueprint, destinationFileName, args.Data)
 		if err != nil {
 			r.events.Emit(&Event{
-				Name:  "render-destination-filename-failed",
+				Name:  "render-iteration-failed",
 				Error: err,
 			})
 			continue
 		}
+		for _, data := range contexts {
+			if condition := blueprint.FileCondition(destinationFileName); condition != "" {
+				generate, err := evaluateCondition(templates, condition, data)
+				if err != nil {
+					r.templateFailed("render-condition-failed", err, blueprint.Name, "", destinationFileName)
+					continue
+				}
+				if !generate {
+					filename, _ := templates.RenderString(destinationFileName, data)
+					r.events.Emit(&Event{
+						Name: "file-skipped-by-condition",
+						Payload: EventPayload{
+							"blueprintName": blueprint.Name,
+							"filename":      filepath.Clean(filename),
+							"condition":     condition,
+						},
+					})
+					continue
+				}
+			}
+			r.renderFile(templates, blueprint, args.Destination, destinationFileName, templateName, data)
+		}
+	}
+
+	if err := r.renderSkeleton(templates, blueprint, args.Destination, args.Data); err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return &RenderFailedError{Blueprint: blueprint.Name, Errors: failures}
+	}
+	return nil
+}
+
+// renderFile renders a single file of blueprint into destination.
+//
+// Templates marked with FileActionCopy are copied verbatim, as are
+// binary templates unless they are marked with FileActionRender.  The
+// permissions of the generated file are taken from the blueprint or,
+// if the blueprint does not specify any, from the template.
+//
+// If the file cannot be generated, nothing is left at its destination.
+func (r *RenderBlueprintToFileSystem) renderFile(templates *HTMLTemplateEngine, blueprint *Blueprint, destination, destinationFileName, templateName string, data interface{}) {
+	outputFilePathTemplate := filepath.Join(destination, destinationFileName)
+	outputFilePath, err := templates.RenderString(outputFilePathTemplate, data)
+	if err != nil {
+		r.templateFailed("render-destination-filename-failed", err, blueprint.Name, "", destinationFileName)
+		return
+	}
+	if !insideDirectory(destination, outputFilePath) {
+		r.events.Emit(&Event{
+			Name:  "render-destination-filename-failed",
+			Error: NewFileSystemError("render", outputFilePath, ErrOutsideDestination),
+			Payload: EventPayload{
+				"blueprintName": blueprint.Name,
+				"filename":      outputFilePath,
+			},
+		})
+		return
+	}
+
+	sourcePath := filepath.Join(BlueprintTemplateDirectory(blueprint.Name), templateName)
+	action := blueprint.FileAction(destinationFileName)
+	copied := false
+	if action != FileActionRender {
+		copied, err = copyFile(r.fs, sourcePath, outputFilePath, action == FileActionCopy)
+		if err != nil {
+			r.events.Emit(&Event{
+				Name:  "copy-template-failed",
+				Error: err,
+			})
+			return
+		}
+	}
+
+	if !copied {
 		destinationFile, err := r.fs.Create(outputFilePath)
 		if err != nil {
 			r.events.Emit(&Event{
 				Name:  "create-destination-file-failed",
 				Error: err,
 			})
-			continue
+			return
 		}
-		err = templates.RenderTemplate(destinationFile, templateName, args.Data)
+		err = templates.RenderTemplate(destinationFile, templateName, data)
 		if err != nil {
 			destinationFile.Close()
+			r.fs.Remove(outputFilePath)
+			r.templateFailed("render-template-failed", err, blueprint.Name, templateName, outputFilePath)
+			return
+		}
+		destinationFile.Close()
+	}
+
+	mode := sourceFileMode(r.fs, sourcePath)
+	if explicitMode, found := blueprint.Modes[destinationFileName]; found {
+		mode = explicitMode
+	}
+	if mode != DefaultFileMode {
+		if err := r.fs.Chmod(outputFilePath, mode); err != nil {
+			r.fs.Remove(outputFilePath)
 			r.events.Emit(&Event{
-				Name:  "render-template-failed",
+				Name:  "chmod-destination-file-failed",
 				Error: err,
 			})
-			continue
+			return
 		}
-		destinationFile.Close()
-		r.events.Emit(&Event{
-			Name: "template-rendered",
-			Payload: EventPayload{
-				"filename": outputFilePath,
-				"template": templateName,
-			},
-		})
 	}
+	r.events.Emit(&Event{
+		Name: "template-rendered",
+		Payload: EventPayload{
+			"filename": outputFilePath,
+			"template": templateName,
+			"copied":   copied,
+		},
+	})
+}
+
+// insideDirectory returns true if path is located below dir.
+func insideDirectory(dir, path string) bool {
+	relative, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	if err != nil || relative == "." {
+		return false
+	}
+	return relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
 
-	return nil
+// templateFailed emits an event called name for an error that occurred
+// while rendering a template of the named blueprint into destination.
+//
+// If err is a *TemplateError, it is completed with the blueprint and
+// destination and the event's payload describes the location of the
+// error.  The template is only filled in if the error does not name
+// one already, e.g. because it occurred in another template included
+// by the one being rendered.
+func (r *RenderBlueprintToFileSystem) templateFailed(name string, err error, blueprintName, templateName, destination string) {
+	payload := EventPayload{
+		"blueprintName": blueprintName,
+		"filename":      destination,
+	}
+	if templateErr, ok := err.(*TemplateError); ok {
+		templateErr.Blueprint = blueprintName
+		templateErr.Destination = destination
+		if templateErr.Template == "" {
+			templateErr.Template = templateName
+		}
+		templateName = templateErr.Template
+		payload["line"] = templateErr.Line
+		payload["column"] = templateErr.Column
+	}
+	if templateName != "" {
+		payload["template"] = templateName
+	}
+	r.events.Emit(&Event{
+		Name:    name,
+		Error:   err,
+		Payload: payload,
+	})
+}
+
+// evaluateCondition renders condition with data and reports whether
+// the result counts as true.
+func evaluateCondition(templates TemplateEngine, condition string, data interface{}) (bool, error) {
+	result, err := templates.RenderString(condition, data)
+	if err != nil {
+		return false, err
+	}
+	switch strings.TrimSpace(result) {
+	case "", "false", "0", "<no value>":
+		return false, nil
+	}
+	return true, nil
+}
+
+// fileContexts returns the data with which the file for
+// destinationFileName is rendered, once per generated file.
+func fileContexts(blueprint *Blueprint, destinationFileName string, data interface{}) ([]interface{}, error) {
+	name := blueprint.FileIteration(destinationFileName)
+	if name == "" {
+		return []interface{}{data}, nil
+	}
+	context, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: cannot iterate over %q without named values", destinationFileName, name)
+	}
+
+	var value interface{} = context
+	for _, key := range strings.Split(name, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = m[key]
+	}
+
+	items := []interface{}{}
+	switch v := value.(type) {
+	case nil:
+	case []interface{}:
+		items = v
+	case []string:
+		for _, item := range v {
+			items = append(items, item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			items = append(items, map[string]interface{}{"key": key, "value": v[key]})
+		}
+	default:
+		return nil, fmt.Errorf("%s: cannot iterate over %q of type %T", destinationFileName, name, value)
+	}
+
+	contexts := make([]interface{}, len(items))
+	for i, item := range items {
+		itemContext := make(map[string]interface{}, len(context)+2)
+		for key, value := range context {
+			itemContext[key] = value
+		}
+		itemContext["item"] = item
+		itemContext["index"] = i
+		contexts[i] = itemContext
+	}
+	return contexts, nil
 }
@@ -1,12 +1,26 @@
 package dux_test
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/dhamidi/dux"
 	h "github.com/dhamidi/dux/testing"
 )
 
+// renderFailed executes command and fails the test unless it returns
+// a *dux.RenderFailedError.
+func renderFailed(t *testing.T, app *dux.Application, command *dux.RenderBlueprint) *dux.RenderFailedError {
+	t.Helper()
+	err := app.Execute(command)
+	renderErr, ok := err.(*dux.RenderFailedError)
+	if !ok {
+		t.Fatalf("Expected *dux.RenderFailedError, got %T: %v", err, err)
+	}
+	return renderErr
+}
+
 func TestApp_RenderBlueprint_renders_blueprints_that_have_been_previously_created(t *testing.T) {
 	app := h.NewApp()
 	do := h.FailOnExecuteError(t, app)
@@ -55,7 +69,7 @@ func TestApp_RenderBlueprint_emits_an_event_if_destination_filename_is_invalid_t
 	do(h.CreateBlueprint("a"))
 	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
 	do(h.DefineBlueprintFile("a", "{{.n}-file", "x.tmpl"))
-	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
 	h.AssertEvent(t, app.EventStore, "render-destination-filename-failed", dux.EventPayload{})
 }
 
@@ -69,7 +83,7 @@ func TestApp_RenderBlueprint_emits_an_event_if_destination_file_cannot_be_opened
 	do(h.CreateBlueprint("a"))
 	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
 	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
-	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
 	h.AssertEvent(t, app.EventStore, "create-destination-file-failed", dux.EventPayload{})
 }
 
@@ -83,7 +97,7 @@ func TestApp_RenderBlueprint_emits_an_event_if_destination_file_cannot_be_writte
 	do(h.CreateBlueprint("a"))
 	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
 	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
-	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
 	h.AssertEvent(t, app.EventStore, "render-template-failed", dux.EventPayload{})
 }
 
@@ -93,6 +107,246 @@ func TestApp_RenderBlueprint_emits_an_event_if_template_is_syntactically_invalid
 	do(h.CreateBlueprint("a"))
 	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}"))
 	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
-	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
 	h.AssertEvent(t, app.EventStore, "render-template-failed", dux.EventPayload{})
 }
+
+func TestApp_RenderBlueprint_skips_files_whose_condition_is_false(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{.n}}-test", TemplateName: "x.tmpl", Condition: "{{.with_tests}}"})
+	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1, "with_tests": false}))
+	h.AssertEvent(t, app.EventStore, "file-skipped-by-condition",
+		dux.EventPayload{"filename": "1-test", "condition": "{{.with_tests}}"},
+	)
+	h.AssertNoEvent(t, app.EventStore, "template-rendered", dux.EventPayload{})
+}
+
+func TestApp_RenderBlueprint_skips_files_whose_condition_refers_to_missing_values(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "x-test", TemplateName: "x.tmpl", Condition: "{{.with_tests}}"})
+	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	h.AssertEvent(t, app.EventStore, "file-skipped-by-condition", dux.EventPayload{"filename": "x-test"})
+}
+
+func TestApp_RenderBlueprint_renders_files_whose_condition_is_true(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "x-test", TemplateName: "x.tmpl", Condition: "{{.with_tests}}"})
+	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1, "with_tests": true}))
+	h.AssertFileContents(t, app.FileSystem, "staging/x-test", "1")
+}
+
+func TestApp_RenderBlueprint_renders_one_file_per_element_of_a_list(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.index}}: add {{.item}} to {{.table}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{.index}}-add-{{.item}}", TemplateName: "x.tmpl", Each: "fields"})
+	do(h.RenderBlueprint("a", map[string]interface{}{"table": "users", "fields": []interface{}{"name", "age"}}))
+	h.AssertTree(t, app.FileSystem, "staging", map[string]string{
+		"0-add-name": "0: add name to users",
+		"1-add-age":  "1: add age to users",
+	})
+}
+
+func TestApp_RenderBlueprint_renders_one_file_per_entry_of_a_map(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.item.key}} {{.item.value}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "add-{{.item.key}}", TemplateName: "x.tmpl", Each: "model.fields", Condition: `{{ ne .item.key "id" }}`})
+	do(h.RenderBlueprint("a", map[string]interface{}{
+		"model": map[string]interface{}{
+			"fields": map[string]interface{}{"id": "int", "name": "string"},
+		},
+	}))
+	h.AssertTree(t, app.FileSystem, "staging", map[string]string{
+		"add-name": "name string",
+	})
+	h.AssertEvent(t, app.EventStore, "file-skipped-by-condition", dux.EventPayload{"filename": "add-id"})
+}
+
+func TestApp_RenderBlueprint_emits_an_event_if_iterating_over_a_scalar(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.item}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "{{.item}}", TemplateName: "x.tmpl", Each: "n"})
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	h.AssertEvent(t, app.EventStore, "render-iteration-failed", dux.EventPayload{})
+}
+
+func TestApp_RenderBlueprint_copies_templates_marked_as_copy_verbatim(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
+	do(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "x-file", TemplateName: "x.tmpl", Action: dux.FileActionCopy})
+	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	h.AssertFileContents(t, app.FileSystem, "staging/x-file", "{{.n}}")
+	h.AssertEvent(t, app.EventStore, "template-rendered", dux.EventPayload{"filename": "staging/x-file", "copied": true})
+}
+
+func TestApp_RenderBlueprint_copies_binary_templates_verbatim(t *testing.T) {
+	binary := "\x89PNG\x00\xff" + strings.Repeat("{{.n}}", 2000)
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	writeFile(t, app.FileSystem, "blueprints/a/templates/logo.png", binary)
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}}"))
+	do(h.DefineBlueprintFile("a", "logo.png", "logo.png"))
+	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
+	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+	h.AssertFileContents(t, app.FileSystem, "staging/logo.png", binary)
+	h.AssertFileContents(t, app.FileSystem, "staging/x-file", "1")
+}
+
+func TestApp_RenderBlueprint_rejects_unknown_actions(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	err := app.Execute(&dux.DefineBlueprintFile{BlueprintName: "a", FileName: "x-file", TemplateName: "x.tmpl", Action: "move"})
+	if err == nil {
+		t.Fatalf("Expected an error for an unknown action")
+	}
+}
+
+func TestApp_RenderBlueprint_preserves_the_mode_of_templates(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "run.sh", "#!/bin/sh\necho {{.n}}"))
+	if err := app.FileSystem.Chmod("blueprints/a/templates/run.sh", 0700); err != nil {
+		t.Fatal(err)
+	}
+	do(h.DefineBlueprintFile("a", "run.sh", "run.sh"))
+	do(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+
+	info, err := app.FileSystem.Stat("staging/run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := info.Mode().Perm(), os.FileMode(0700); got != want {
+		t.Fatalf("Expected mode %v, got %v", want, got)
+	}
+}
+
+func TestApp_RenderBlueprint_reports_location_of_template_errors(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "first\nsecond {{ .n.Missing }}\nthird"))
+	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+
+	h.AssertEvent(t, app.EventStore, "render-template-failed", dux.EventPayload{
+		"blueprintName": "a",
+		"template":      "x.tmpl",
+		"filename":      "staging/x-file",
+		"line":          2,
+	})
+	e := h.Events(t, app.EventStore, "render-template-failed")[0]
+	templateErr, ok := e.Error.(*dux.TemplateError)
+	if !ok {
+		t.Fatalf("Expected *dux.TemplateError, got %T", e.Error)
+	}
+	if !strings.HasPrefix(templateErr.Error(), "x.tmpl:2:") {
+		t.Errorf("Expected error to start with location, got %q", templateErr.Error())
+	}
+	if !strings.Contains(templateErr.Snippet, "   2 | second {{ .n.Missing }}") {
+		t.Errorf("Expected offending line in snippet, got:\n%s", templateErr.Snippet)
+	}
+}
+
+func TestApp_RenderBlueprint_ignores_syntax_errors_in_other_templates(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "broken.tmpl", "{{ .n }"))
+	do(h.DefineBlueprintTemplate("a", "partial.tmpl", "[{{ .n }}]"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", `{{ template "partial.tmpl" . }}`))
+	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
+	do(h.DefineBlueprintFile("a", "y-file", "broken.tmpl"))
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+
+	h.AssertFileContents(t, app.FileSystem, "staging/x-file", "[1]")
+	h.AssertEventCount(t, app.EventStore, "render-template-failed", 1)
+	h.AssertEvent(t, app.EventStore, "render-template-failed", dux.EventPayload{"template": "broken.tmpl"})
+}
+
+func TestApp_RenderBlueprint_returns_the_errors_of_all_failed_files(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.n}"))
+	do(h.DefineBlueprintTemplate("a", "y.tmpl", "{{.n.Missing}}"))
+	do(h.DefineBlueprintTemplate("a", "z.tmpl", "{{.n}}"))
+	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
+	do(h.DefineBlueprintFile("a", "y-file", "y.tmpl"))
+	do(h.DefineBlueprintFile("a", "z-file", "z.tmpl"))
+
+	renderErr := renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+
+	if len(renderErr.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d: %s", len(renderErr.Errors), renderErr)
+	}
+	if renderErr.Blueprint != "a" {
+		t.Errorf("Expected error for blueprint a, got %q", renderErr.Blueprint)
+	}
+	h.AssertFileContents(t, app.FileSystem, "staging/z-file", "1")
+}
+
+func TestApp_RenderBlueprint_rejects_file_names_outside_of_the_destination(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "overwritten"))
+	do(h.DefineBlueprintFile("a", "{{.p}}.txt", "x.tmpl"))
+	writeFile(t, app.FileSystem, "keep.txt", "original")
+
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"p": "../keep"}))
+
+	h.AssertFileContents(t, app.FileSystem, "keep.txt", "original")
+	e := h.Events(t, app.EventStore, "render-destination-filename-failed")[0]
+	if fsErr, ok := e.Error.(*dux.FileSystemError); !ok || fsErr.Err != dux.ErrOutsideDestination {
+		t.Fatalf("Expected ErrOutsideDestination, got %#v", e.Error)
+	}
+}
+
+func TestApp_RenderBlueprint_removes_files_that_failed_to_render(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "partial output {{.n.Missing}}"))
+	do(h.DefineBlueprintFile("a", "x-file", "x.tmpl"))
+
+	renderFailed(t, app, h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+
+	if exists, _ := app.FileSystem.Exists("staging/x-file"); exists {
+		t.Fatalf("Expected staging/x-file to be removed")
+	}
+}
+
+func TestApp_RenderBlueprint_renders_templates_in_subdirectories(t *testing.T) {
+	app := h.SetupApp(t, dux.NewOnDiskFileSystem(t.TempDir()),
+		h.CreateBlueprint("a"),
+		h.DefineBlueprintTemplate("a", "sub/inner.tmpl", "inner {{.n}}"),
+		h.DefineBlueprintTemplate("a", "x.tmpl", `{{template "sub/inner.tmpl" .}}!`),
+		h.DefineBlueprintFile("a", "x-file", "x.tmpl"),
+		h.DefineBlueprintFile("a", "inner-file", "sub/inner.tmpl"),
+	)
+	h.FailOnExecuteError(t, app)(h.RenderBlueprint("a", map[string]interface{}{"n": 1}))
+
+	h.AssertTree(t, app.FileSystem, "staging", map[string]string{
+		"x-file":     "inner 1!",
+		"inner-file": "inner 1",
+	})
+}
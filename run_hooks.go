@@ -0,0 +1,245 @@
+package dux
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// DefaultHookTimeout is the time after which a hook is stopped if
+// RunHooks does not specify a timeout.
+const DefaultHookTimeout = 2 * time.Minute
+
+// hookWaitDelay is the time to wait for the output of a hook to be
+// closed after the hook has been stopped.  Processes started by the
+// hook in the background might keep it open otherwise.
+const hookWaitDelay = time.Second
+
+// RunHooks runs the post install hooks of a blueprint after its files
+// have been installed.
+type RunHooks struct {
+	BlueprintName string
+	Data          interface{}   // The context used for rendering the blueprint
+	Files         []string      // The installed files
+	Timeout       time.Duration // The time after which a single hook is stopped
+}
+
+// CommandName implements Command
+func (c *RunHooks) CommandName() string { return "run-hooks" }
+
+// HookFiles lists the files passed to a hook.  When printed in a
+// template, the file names are quoted for the shell and separated by
+// spaces, so that every file becomes a separate word.
+type HookFiles []string
+
+// String implements fmt.Stringer
+func (files HookFiles) String() string {
+	quoted := make([]string, len(files))
+	for i, file := range files {
+		quoted[i] = shellQuote(file)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes s for use as a single word in a shell command.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=+,:@%") == "" {
+		return s
+	}
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// RunHooksInShell runs the hooks of a blueprint using a shell in the
+// root directory of the file system.
+//
+// For every hook a "hook-started" event is emitted, followed by one
+// "hook-output" event per line written by the hook.  If the hook
+// fails, a "hook-failed" event is emitted and the remaining hooks are
+// not run.  Otherwise a "hook-finished" event is emitted.
+type RunHooksInShell struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+
+	// Shell is the command line to which the rendered hook is
+	// appended as the last argument.
+	Shell []string
+}
+
+// NewRunHooksInShell returns a command handler running hooks with sh.
+func NewRunHooksInShell(fs FileSystem, store Store, events EventStore) *RunHooksInShell {
+	return &RunHooksInShell{
+		fs:     fs,
+		store:  store,
+		events: events,
+		Shell:  []string{"sh", "-c"},
+	}
+}
+
+// Execute implements CommandHandler
+func (h *RunHooksInShell) Execute(command Command) error {
+	args := command.(*RunHooks)
+	blueprint := new(Blueprint)
+	if err := h.store.Get(args.BlueprintName, blueprint); err != nil {
+		return err
+	}
+	timeout := args.Timeout
+	if timeout == 0 {
+		timeout = DefaultHookTimeout
+	}
+
+	data := hookContext(args.Data, args.Files)
+	funcs := template.FuncMap(NewHTMLTemplateEngine(BlueprintTemplateDirectory(blueprint.Name), h.fs).TemplateFuncs())
+	for _, hook := range blueprint.PostInstall {
+		commandLine, err := renderHook(hook, funcs, data)
+		if err == nil {
+			err = h.run(commandLine, timeout)
+		}
+		if err != nil {
+			h.events.Emit(&Event{
+				Name:    "hook-failed",
+				Payload: EventPayload{"blueprintName": blueprint.Name, "command": hook},
+				Error:   fmt.Errorf("%s: %s", hook, err),
+			})
+			return nil
+		}
+		h.events.Emit(&Event{
+			Name:    "hook-finished",
+			Payload: EventPayload{"blueprintName": blueprint.Name, "command": commandLine},
+		})
+	}
+	return nil
+}
+
+// run runs commandLine in the root of the file system, emitting its
+// output line by line.  When the timeout expires, the hook is killed
+// together with the processes it started.
+func (h *RunHooksInShell) run(commandLine string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.Shell[0], append(h.Shell[1:], commandLine)...)
+	startProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+	cmd.WaitDelay = hookWaitDelay
+	if root, ok := h.fs.(interface{ Root() string }); ok {
+		cmd.Dir = root.Root()
+	}
+	output, writer := io.Pipe()
+	cmd.Stdout, cmd.Stderr = writer, writer
+
+	h.events.Emit(&Event{
+		Name:    "hook-started",
+		Payload: EventPayload{"command": commandLine},
+	})
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		writer.Close()
+		done <- err
+	}()
+
+	lines := bufio.NewScanner(output)
+	for lines.Scan() {
+		h.events.Emit(&Event{
+			Name:    "hook-output",
+			Payload: EventPayload{"command": commandLine, "line": lines.Text()},
+		})
+	}
+	io.Copy(ioutil.Discard, output)
+
+	err := <-done
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// hookQuoteFunc is the name under which quoteHookValue is made
+// available to hooks.  It cannot be called from a hook directly.
+const hookQuoteFunc = "_dux_quote"
+
+// quoteHookValue quotes value for use as a single word in a shell
+// command.  HookFiles are quoted file by file instead.
+func quoteHookValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return shellQuote("")
+	case HookFiles:
+		return value.String()
+	}
+	return shellQuote(fmt.Sprint(value))
+}
+
+// renderHook renders the command line of hook with data.
+//
+// Every value printed by hook is quoted for the shell, so that values
+// from the context cannot inject shell commands.
+func renderHook(hook string, funcs template.FuncMap, data interface{}) (string, error) {
+	tmpl, err := template.New("hook").Funcs(funcs).Funcs(template.FuncMap{hookQuoteFunc: quoteHookValue}).Parse(hook)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tmpl.Templates() {
+		quoteActions(t.Tree.Root)
+	}
+	out := new(strings.Builder)
+	if err := tmpl.Execute(out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// quoteActions appends a call to quoteHookValue to the pipeline of
+// every action below node which prints a value.
+func quoteActions(node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			quoteActions(child)
+		}
+	case *parse.ActionNode:
+		if len(node.Pipe.Decl) > 0 {
+			return
+		}
+		node.Pipe.Cmds = append(node.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      node.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(hookQuoteFunc).SetPos(node.Pos)},
+		})
+	case *parse.IfNode:
+		quoteActions(node.List)
+		quoteActions(node.ElseList)
+	case *parse.RangeNode:
+		quoteActions(node.List)
+		quoteActions(node.ElseList)
+	case *parse.WithNode:
+		quoteActions(node.List)
+		quoteActions(node.ElseList)
+	}
+}
+
+// hookContext returns data extended with the installed files.
+func hookContext(data interface{}, files []string) map[string]interface{} {
+	result := map[string]interface{}{}
+	if values, ok := data.(map[string]interface{}); ok {
+		for key, value := range values {
+			result[key] = value
+		}
+	}
+	result["files"] = HookFiles(files)
+	return result
+}
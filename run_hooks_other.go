@@ -0,0 +1,15 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package dux
+
+import "os/exec"
+
+// startProcessGroup does nothing on this platform.
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills cmd.  Processes started by cmd are only
+// stopped by closing their output after hookWaitDelay.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
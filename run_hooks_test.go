@@ -0,0 +1,89 @@
+package dux_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestRunHooksInShell_emits_output_of_hooks_line_by_line(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"), h.DefineBlueprintHook("a", "echo {{.name}}; echo {{.files}}"))
+	do := h.FailOnExecuteError(t, app)
+	do(&dux.RunHooks{
+		BlueprintName: "a",
+		Data:          map[string]interface{}{"name": "world"},
+		Files:         []string{"a.txt", "b c.txt"},
+	})
+
+	h.AssertEventSequence(t, app.EventStore, "hook-started", "hook-output", "hook-output", "hook-finished")
+	h.AssertEvent(t, app.EventStore, "hook-started", dux.EventPayload{"command": "echo world; echo a.txt 'b c.txt'"})
+	lines := []string{}
+	for _, e := range h.Events(t, app.EventStore, "hook-output") {
+		lines = append(lines, e.Payload["line"].(string))
+	}
+	if got, want := strings.Join(lines, "\n"), "world\na.txt b c.txt"; got != want {
+		t.Fatalf("Expected output %q, got %q", want, got)
+	}
+}
+
+func TestRunHooksInShell_quotes_values_for_the_shell(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"), h.DefineBlueprintHook("a", "echo {{.name}} {{range .tags}}{{.}} {{end}}{{.missing}}"))
+	do := h.FailOnExecuteError(t, app)
+	do(&dux.RunHooks{
+		BlueprintName: "a",
+		Data:          map[string]interface{}{"name": "x; echo injected", "tags": []interface{}{"a b", "it's"}},
+	})
+
+	h.AssertEvent(t, app.EventStore, "hook-started", dux.EventPayload{"command": `echo 'x; echo injected' 'a b' 'it'\''s' ''`})
+	lines := []string{}
+	for _, e := range h.Events(t, app.EventStore, "hook-output") {
+		lines = append(lines, e.Payload["line"].(string))
+	}
+	if got, want := strings.Join(lines, "\n"), "x; echo injected a b it's "; got != want {
+		t.Fatalf("Expected output %q, got %q", want, got)
+	}
+}
+
+func TestRunHooksInShell_stops_after_the_first_failing_hook(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"), h.DefineBlueprintHook("a", "exit 3"), h.DefineBlueprintHook("a", "echo not reached"))
+	do := h.FailOnExecuteError(t, app)
+	do(&dux.RunHooks{BlueprintName: "a"})
+
+	h.AssertEventCount(t, app.EventStore, "hook-failed", 1)
+	h.AssertEventCount(t, app.EventStore, "hook-started", 1)
+	h.AssertEventCount(t, app.EventStore, "hook-output", 0)
+}
+
+func TestRunHooksInShell_stops_hooks_after_the_timeout(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"), h.DefineBlueprintHook("a", "exec sleep 5"))
+	do := h.FailOnExecuteError(t, app)
+	started := time.Now()
+	do(&dux.RunHooks{BlueprintName: "a", Timeout: 50 * time.Millisecond})
+
+	if elapsed := time.Since(started); elapsed > 4*time.Second {
+		t.Fatalf("Expected hook to be stopped, took %s", elapsed)
+	}
+	events, _ := app.EventStore.All()
+	for _, e := range events {
+		if e.Name == "hook-failed" && strings.Contains(e.Error.Error(), "timed out") {
+			return
+		}
+	}
+	t.Fatalf("Expected hook-failed event with timeout, got %v", events)
+}
+
+func TestRunHooksInShell_stops_processes_started_by_hooks_after_the_timeout(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"), h.DefineBlueprintHook("a", "sleep 5; echo done"))
+	do := h.FailOnExecuteError(t, app)
+	started := time.Now()
+	do(&dux.RunHooks{BlueprintName: "a", Timeout: 300 * time.Millisecond})
+
+	if elapsed := time.Since(started); elapsed > 4*time.Second {
+		t.Fatalf("Expected hook to be stopped, took %s", elapsed)
+	}
+	h.AssertEventCount(t, app.EventStore, "hook-failed", 1)
+	h.AssertEventCount(t, app.EventStore, "hook-output", 0)
+}
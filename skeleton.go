@@ -0,0 +1,211 @@
+package dux
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SkeletonIgnoreFile is the name of the file in the skeleton
+// directory of a blueprint that lists patterns of files which should
+// not be rendered, one per line.
+//
+// Patterns use the syntax of filepath.Match.  Patterns without a
+// slash are matched against every path segment, others against the
+// whole path relative to the skeleton directory.  Empty lines and
+// lines starting with # are ignored.
+const SkeletonIgnoreFile = ".duxignore"
+
+// TemplateSuffix is stripped from the names of files in the skeleton
+// directory when rendering them.
+const TemplateSuffix = ".tmpl"
+
+// renderSkeleton renders all files in the skeleton directory of
+// blueprint into destination.
+//
+// The path of every file is rendered segment by segment.  If any
+// segment renders to an empty string, the file is skipped.  The
+// contents of text files are rendered as templates, binary files are
+// copied verbatim.
+func (r *RenderBlueprintToFileSystem) renderSkeleton(templates TemplateEngine, blueprint *Blueprint, destination string, data interface{}) error {
+	dir := BlueprintSkeletonDirectory(blueprint.Name)
+	if exists, err := r.fs.Exists(dir); err != nil || !exists {
+		return err
+	}
+	patterns, err := readIgnorePatterns(r.fs, filepath.Join(dir, SkeletonIgnoreFile))
+	if err != nil {
+		return err
+	}
+
+	return r.fs.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil || relative == "." {
+			return err
+		}
+		if relative == SkeletonIgnoreFile || isIgnored(patterns, relative) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		outputFilePath, err := renderSkeletonPath(templates, relative, data)
+		if err != nil {
+			r.templateFailed("render-destination-filename-failed", err, blueprint.Name, "", relative)
+			return nil
+		}
+		if outputFilePath == "" {
+			return nil
+		}
+		outputFilePath = filepath.Join(destination, outputFilePath)
+		if !insideDirectory(destination, outputFilePath) {
+			r.events.Emit(&Event{
+				Name:  "render-destination-filename-failed",
+				Error: NewFileSystemError("render", outputFilePath, ErrOutsideDestination),
+				Payload: EventPayload{
+					"blueprintName": blueprint.Name,
+					"filename":      outputFilePath,
+				},
+			})
+			return nil
+		}
+		r.renderSkeletonFile(templates, blueprint.Name, path, outputFilePath, data)
+		return nil
+	})
+}
+
+// renderSkeletonFile renders the skeleton file at path into
+// outputFilePath.  Binary files are copied verbatim.  The permissions
+// of the file are preserved.
+func (r *RenderBlueprintToFileSystem) renderSkeletonFile(templates TemplateEngine, blueprintName, path, outputFilePath string, data interface{}) {
+	copied, err := copyFile(r.fs, path, outputFilePath, false)
+	if err != nil {
+		r.events.Emit(&Event{
+			Name:  "copy-template-failed",
+			Error: err,
+		})
+		return
+	}
+
+	if !copied {
+		contents, err := ReadFile(r.fs, path)
+		if err != nil {
+			r.events.Emit(&Event{
+				Name:  "read-skeleton-file-failed",
+				Error: err,
+			})
+			return
+		}
+		rendered, err := templates.RenderString(string(contents), data)
+		if err != nil {
+			r.templateFailed("render-template-failed", err, blueprintName, path, outputFilePath)
+			return
+		}
+		destinationFile, err := r.fs.Create(outputFilePath)
+		if err != nil {
+			r.events.Emit(&Event{
+				Name:  "create-destination-file-failed",
+				Error: err,
+			})
+			return
+		}
+		_, err = io.WriteString(destinationFile, rendered)
+		destinationFile.Close()
+		if err != nil {
+			r.fs.Remove(outputFilePath)
+			r.events.Emit(&Event{
+				Name:  "create-destination-file-failed",
+				Error: err,
+			})
+			return
+		}
+	}
+
+	if mode := sourceFileMode(r.fs, path); mode != DefaultFileMode {
+		if err := r.fs.Chmod(outputFilePath, mode); err != nil {
+			r.fs.Remove(outputFilePath)
+			r.events.Emit(&Event{
+				Name:  "chmod-destination-file-failed",
+				Error: err,
+			})
+			return
+		}
+	}
+	r.events.Emit(&Event{
+		Name: "template-rendered",
+		Payload: EventPayload{
+			"filename": outputFilePath,
+			"template": path,
+			"copied":   copied,
+		},
+	})
+}
+
+// renderSkeletonPath renders every segment of relative as a template
+// and strips TemplateSuffix from the result.  If any segment renders
+// to an empty string, an empty path is returned.
+func renderSkeletonPath(templates TemplateEngine, relative string, data interface{}) (string, error) {
+	segments := strings.Split(filepath.ToSlash(relative), "/")
+	for i, segment := range segments {
+		rendered, err := templates.RenderString(segment, data)
+		if err != nil {
+			return "", err
+		}
+		if rendered == "" {
+			return "", nil
+		}
+		segments[i] = rendered
+	}
+	return strings.TrimSuffix(filepath.Join(segments...), TemplateSuffix), nil
+}
+
+// readIgnorePatterns returns the patterns listed in filename, or no
+// patterns if the file does not exist.
+func readIgnorePatterns(fs FileSystem, filename string) ([]string, error) {
+	if exists, err := fs.Exists(filename); err != nil || !exists {
+		return nil, err
+	}
+	contents, err := ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.Trim(line, "/"))
+	}
+	return patterns, scanner.Err()
+}
+
+// isIgnored reports whether relative matches any of patterns.
+func isIgnored(patterns []string, relative string) bool {
+	relative = filepath.ToSlash(relative)
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			if matched, _ := filepath.Match(pattern, relative); matched {
+				return true
+			}
+			continue
+		}
+		for _, segment := range strings.Split(relative, "/") {
+			if matched, _ := filepath.Match(pattern, segment); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
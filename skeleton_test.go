@@ -0,0 +1,80 @@
+package dux_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestApp_RenderBlueprint_renders_skeleton_recursively(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{
+		"README.md":                       "# {{.name}}",
+		"cmd/{{.name}}/main.go.tmpl":      "package main // {{.name}}",
+		"{{if .with_tests}}test{{end}}/x": "test",
+	})
+	do := h.FailOnExecuteError(t, app)
+	do(h.RenderBlueprint("a", map[string]interface{}{"name": "app"}))
+
+	h.AssertTree(t, app.FileSystem, "staging", map[string]string{
+		"README.md":       "# app",
+		"cmd/app/main.go": "package main // app",
+	})
+	h.AssertEvent(t, app.EventStore, "template-rendered", dux.EventPayload{"filename": "staging/README.md"})
+}
+
+func TestApp_RenderBlueprint_copies_binary_skeleton_files_verbatim(t *testing.T) {
+	binary := "\x00{{.name}}\xff"
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{"logo.png": binary})
+	do := h.FailOnExecuteError(t, app)
+	do(h.RenderBlueprint("a", map[string]interface{}{"name": "app"}))
+
+	h.AssertFileContents(t, app.FileSystem, "staging/logo.png", binary)
+}
+
+func TestApp_RenderBlueprint_ignores_skeleton_files_matching_ignore_patterns(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{
+		".duxignore":        "# editor files\n*.swp\nvendor/\ndocs/draft.md\n",
+		"main.go":           "package main",
+		".main.go.swp":      "junk",
+		"vendor/lib/lib.go": "package lib",
+		"docs/draft.md":     "draft",
+		"docs/index.md":     "index",
+	})
+	do := h.FailOnExecuteError(t, app)
+	do(h.RenderBlueprint("a", map[string]interface{}{}))
+
+	h.AssertTree(t, app.FileSystem, "staging", map[string]string{
+		"main.go":       "package main",
+		"docs/index.md": "index",
+	})
+}
+
+func TestImportBlueprintFromArchive_imports_skeleton(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{"cmd/main.go": "package main"})
+	do := h.FailOnExecuteError(t, app)
+	archive := new(bytes.Buffer)
+	do(h.ExportBlueprint("a", archive))
+
+	imported := h.NewApp()
+	h.FailOnExecuteError(t, imported)(h.ImportBlueprint(archive, false))
+	h.AssertFileContents(t, imported.FileSystem, "blueprints/a/skeleton/cmd/main.go", "package main")
+}
+
+func TestApp_RenderBlueprint_rejects_skeleton_paths_outside_of_the_destination(t *testing.T) {
+	app := h.SetupApp(t, nil, h.CreateBlueprint("a"))
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{"{{.p}}/keep.txt": "overwritten"})
+	writeFile(t, app.FileSystem, "keep.txt", "original")
+
+	err := app.Execute(h.RenderBlueprint("a", map[string]interface{}{"p": ".."}))
+
+	if _, ok := err.(*dux.RenderFailedError); !ok {
+		t.Fatalf("Expected *dux.RenderFailedError, got %#v", err)
+	}
+	h.AssertFileContents(t, app.FileSystem, "keep.txt", "original")
+}
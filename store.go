@@ -2,24 +2,69 @@ package dux
 
 import (
 	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // Store provides access to a id-indexed store of persistent objects.
 type Store interface {
+	// Get deserializes the object identified by id into dest.
+	//
+	// If no such object exists, a *NotFoundError is returned.
 	Get(id string, dest interface{}) error
+
+	// Put serializes src and stores it under id, replacing
+	// any previously stored object.
 	Put(id string, src interface{}) error
+
+	// List returns the ids of all objects matching the glob pattern
+	// in lexical order.
 	List(pattern string) ([]string, error)
+
+	// Delete removes the object identified by id.
+	//
+	// If no such object exists, a *NotFoundError is returned.
+	Delete(id string) error
+
+	// Exists returns true if an object is stored under id.
+	Exists(id string) (bool, error)
+}
+
+// NotFoundError is returned by a Store when trying to access an
+// object that does not exist.
+type NotFoundError struct {
+	ID string
+}
+
+// Error implements the error interface
+func (err *NotFoundError) Error() string {
+	return fmt.Sprintf("object %q not found", err.ID)
+}
+
+// IsNotFound returns true if err is a *NotFoundError.
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
 }
 
 // FileSystemStore stores objects in the provided directory on a filesystem.
 //
-// Objects are de- and encoded using JSON
+// Objects can be stored in any of the formats listed in StoreFormats
+// and are identified by their file name without the extension.  New
+// objects are stored as JSON, while existing objects keep the format
+// in which they have been written.
+//
+// If migrations have been configured using WithMigrations, every
+// stored object is stamped with its schema version and objects with
+// an older schema version are migrated when they are loaded.
 type FileSystemStore struct {
-	fs  FileSystem
-	dir string
+	fs         FileSystem
+	dir        string
+	migrations []Migration
 }
 
 // NewFileSystemStore creates a new Store for the given directory and file system.
@@ -30,46 +75,218 @@ func NewFileSystemStore(dir string, fs FileSystem) *FileSystemStore {
 	}
 }
 
-// Get deserializes the file identified by ID as JSON into dest.
+// WithMigrations configures the chain of migrations that is applied
+// to objects when they are loaded from this store.
+func (s *FileSystemStore) WithMigrations(migrations []Migration) *FileSystemStore {
+	s.migrations = migrations
+	return s
+}
+
+// filename returns the name of the file in which the object identified by id is stored in the given format.
+func (s *FileSystemStore) filename(id string, format StoreFormat) string {
+	return filepath.Join(s.dir, id) + format.Extension()
+}
+
+// find returns the format in which the object identified by id is
+// stored, or nil if no such object exists.
+func (s *FileSystemStore) find(id string) (StoreFormat, error) {
+	for _, format := range StoreFormats {
+		exists, err := s.fs.Exists(s.filename(id, format))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return format, nil
+		}
+	}
+	return nil, nil
+}
+
+// Filename returns the name of the file in which the object
+// identified by id is stored.
+func (s *FileSystemStore) Filename(id string) (string, error) {
+	format, err := s.find(id)
+	if err != nil {
+		return "", err
+	}
+	if format == nil {
+		return "", &NotFoundError{ID: id}
+	}
+	return s.filename(id, format), nil
+}
+
+// Get deserializes the file identified by ID into dest.
 func (s *FileSystemStore) Get(id string, dest interface{}) error {
-	f, err := s.fs.Open(filepath.Join(s.dir, id) + ".json")
+	format, err := s.find(id)
 	if err != nil {
 		return err
 	}
+	if format == nil {
+		return &NotFoundError{ID: id}
+	}
+
+	filename := s.filename(id, format)
+	f, err := s.fs.Open(filename)
+	if err != nil {
+		if IsNotExist(err) {
+			return &NotFoundError{ID: id}
+		}
+		return err
+	}
 	defer f.Close()
-	dec := json.NewDecoder(f)
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return err
+	}
 
-	return dec.Decode(dest)
+	document, err := format.Decode(data)
+	if err != nil {
+		if decodeErr, ok := err.(*DecodeError); ok {
+			decodeErr.Filename = filename
+		}
+		return err
+	}
+
+	if s.migrations != nil {
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return &DecodeError{Filename: filename, Err: fmt.Errorf("expected an object, got %T", document)}
+		}
+		if err := Migrate(object, s.migrations); err != nil {
+			return fmt.Errorf("migrating %q: %s", id, err)
+		}
+	}
+
+	if err := reencode(document, dest); err != nil {
+		return &DecodeError{Filename: filename, Err: err}
+	}
+	return nil
 }
 
-// Put serializes src as JSON and writes it to the file identified by id.
+// Put serializes src and writes it to the file identified by id,
+// using the format of the existing file if there is one.
 func (s *FileSystemStore) Put(id string, src interface{}) error {
-	f, err := s.fs.Create(filepath.Join(s.dir, id) + ".json")
+	format, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if format == nil {
+		format = StoreFormats[0]
+	}
+
+	document, err := toDocument(src)
+	if err != nil {
+		return err
+	}
+	if s.migrations != nil {
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("storing %q: expected an object, got %T", id, document)
+		}
+		object[SchemaVersionKey] = len(s.migrations)
+	}
+
+	f, err := s.fs.Create(s.filename(id, format))
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	enc := json.NewEncoder(f)
 
-	return enc.Encode(src)
+	return format.Encode(f, document)
+}
+
+// reencode converts src into dest by encoding src as JSON and decoding the result into dest.
+func reencode(src interface{}, dest interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dest)
 }
 
-// List searches for all json files matching the given glob in the base directory of the file system store.
+// List searches for all files matching the given glob in the base
+// directory of the file system store, in any of the supported
+// formats.  Like with filepath.Glob, the pattern may contain
+// wildcards in directory names as well.
 //
-// The json extension is removed from all filenames before they are returned
+// The extension is removed from all filenames before they are
+// returned and every id is returned only once.
 func (s *FileSystemStore) List(pattern string) ([]string, error) {
-	filenames, err := filepath.Glob(filepath.Join(s.dir, pattern+".json"))
 	result := []string{}
-	if err != nil {
+	idPattern := filepath.Join(s.dir, pattern)
+	if _, err := filepath.Match(idPattern, ""); err != nil {
 		return result, err
 	}
 
-	prefix := s.dir + string(os.PathSeparator)
-	for _, filename := range filenames {
-		withoutExtension := strings.TrimSuffix(filename, ".json")
-		withoutDirectory := strings.TrimPrefix(withoutExtension, prefix)
-		result = append(result, withoutDirectory)
+	root := filepath.Clean(s.dir)
+	separator := string(filepath.Separator)
+	components := strings.Split(idPattern, separator)
+	prefix := root + separator
+	seen := map[string]bool{}
+	err := s.fs.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// Only descend into directories matching the
+			// directory part of the pattern.
+			if path == root {
+				return nil
+			}
+			depth := strings.Count(path, separator) + 1
+			if depth >= len(components) {
+				return filepath.SkipDir
+			}
+			if matches, _ := filepath.Match(strings.Join(components[:depth], separator), path); !matches {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, format := range StoreFormats {
+			if !strings.HasSuffix(path, format.Extension()) {
+				continue
+			}
+			withoutExtension := strings.TrimSuffix(path, format.Extension())
+			if matches, _ := filepath.Match(idPattern, withoutExtension); !matches {
+				continue
+			}
+			withoutDirectory := strings.TrimPrefix(withoutExtension, prefix)
+			if !seen[withoutDirectory] {
+				seen[withoutDirectory] = true
+				result = append(result, withoutDirectory)
+			}
+		}
+		return nil
+	})
+	if err != nil && !IsNotExist(err) {
+		return result, err
 	}
+	sort.Strings(result)
 
 	return result, nil
 }
+
+// Delete removes all files storing the object identified by id.
+func (s *FileSystemStore) Delete(id string) error {
+	deleted := false
+	for _, format := range StoreFormats {
+		err := s.fs.Remove(s.filename(id, format))
+		if err == nil {
+			deleted = true
+			continue
+		}
+		if !IsNotExist(err) {
+			return err
+		}
+	}
+	if !deleted {
+		return &NotFoundError{ID: id}
+	}
+	return nil
+}
+
+// Exists returns true if a file for id exists in the file system in any supported format.
+func (s *FileSystemStore) Exists(id string) (bool, error) {
+	format, err := s.find(id)
+	return format != nil, err
+}
@@ -0,0 +1,189 @@
+package dux
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// StoreFormat describes a serialization format supported by
+// FileSystemStore.
+type StoreFormat interface {
+	// Extension returns the file name extension, including the
+	// leading dot, of files stored in this format.
+	Extension() string
+
+	// Decode parses data into a generic document.
+	//
+	// Errors that can be attributed to a position in data are
+	// returned as *DecodeError.
+	Decode(data []byte) (interface{}, error)
+
+	// Encode writes the generic document to w.
+	Encode(w io.Writer, document interface{}) error
+}
+
+// StoreFormats lists the formats understood by FileSystemStore in
+// order of precedence.  If files for the same id exist in multiple
+// formats, the first format in this list wins.
+var StoreFormats = []StoreFormat{
+	&JSONFormat{},
+	&YAMLFormat{extension: ".yaml"},
+	&YAMLFormat{extension: ".yml"},
+	&TOMLFormat{},
+}
+
+// DecodeError is returned when a stored document cannot be parsed.
+type DecodeError struct {
+	Filename string
+	Line     int // The line on which the error occurred, or 0 if unknown
+	Err      error
+}
+
+// Error implements the error interface
+func (err *DecodeError) Error() string {
+	if err.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", err.Filename, err.Line, err.Err)
+	}
+	return fmt.Sprintf("%s: %s", err.Filename, err.Err)
+}
+
+// JSONFormat implements StoreFormat using encoding/json.
+type JSONFormat struct{}
+
+// Extension implements StoreFormat
+func (f *JSONFormat) Extension() string { return ".json" }
+
+// Decode implements StoreFormat
+func (f *JSONFormat) Decode(data []byte) (interface{}, error) {
+	var document interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&document); err != nil {
+		offset := int64(0)
+		switch err := err.(type) {
+		case *json.SyntaxError:
+			offset = err.Offset
+		case *json.UnmarshalTypeError:
+			offset = err.Offset
+		}
+		return nil, &DecodeError{Line: lineAtOffset(data, offset), Err: err}
+	}
+	return document, nil
+}
+
+// Encode implements StoreFormat
+func (f *JSONFormat) Encode(w io.Writer, document interface{}) error {
+	return json.NewEncoder(w).Encode(document)
+}
+
+// YAMLFormat implements StoreFormat using gopkg.in/yaml.v3.
+type YAMLFormat struct {
+	extension string
+}
+
+// Extension implements StoreFormat
+func (f *YAMLFormat) Extension() string { return f.extension }
+
+// yamlLinePattern extracts the line number from errors reported by the YAML parser.
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// Decode implements StoreFormat
+func (f *YAMLFormat) Decode(data []byte) (interface{}, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		line := 0
+		if match := yamlLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		return nil, &DecodeError{Line: line, Err: err}
+	}
+	return document, nil
+}
+
+// Encode implements StoreFormat
+func (f *YAMLFormat) Encode(w io.Writer, document interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(document); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// TOMLFormat implements StoreFormat using github.com/BurntSushi/toml.
+type TOMLFormat struct{}
+
+// Extension implements StoreFormat
+func (f *TOMLFormat) Extension() string { return ".toml" }
+
+// Decode implements StoreFormat
+func (f *TOMLFormat) Decode(data []byte) (interface{}, error) {
+	document := map[string]interface{}{}
+	if _, err := toml.Decode(string(data), &document); err != nil {
+		line := 0
+		if parseErr, ok := err.(toml.ParseError); ok {
+			line = parseErr.Position.Line
+		}
+		return nil, &DecodeError{Line: line, Err: err}
+	}
+	return document, nil
+}
+
+// Encode implements StoreFormat
+func (f *TOMLFormat) Encode(w io.Writer, document interface{}) error {
+	return toml.NewEncoder(w).Encode(document)
+}
+
+// lineAtOffset returns the 1-based line number of the byte at offset in data.
+func lineAtOffset(data []byte, offset int64) int {
+	if offset <= 0 {
+		return 0
+	}
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// toDocument converts src into a generic document consisting of
+// maps, slices, strings, booleans and numbers.  Keys are named like
+// encoding/json names them, so the json tags of src decide the keys
+// used in every format.
+//
+// Integral numbers are represented as int64, so that formats
+// distinguishing between integers and floats do not turn integers
+// into floats.
+func toDocument(src interface{}) (interface{}, error) {
+	var document interface{}
+	if err := reencode(src, &document); err != nil {
+		return nil, err
+	}
+	return normalizeNumbers(document), nil
+}
+
+// normalizeNumbers converts all integral float64 values in document to int64.
+func normalizeNumbers(document interface{}) interface{} {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = normalizeNumbers(v)
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = normalizeNumbers(v)
+		}
+	case float64:
+		if value == math.Trunc(value) && math.Abs(value) < math.MaxInt64 {
+			return int64(value)
+		}
+	}
+	return document
+}
@@ -0,0 +1,122 @@
+package dux_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestFileSystemStore_Get_reads_blueprints_written_in_YAML(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.yaml", `# A blueprint written by hand
+name: a
+description: |
+  Multi-line
+  description
+files:
+  "{{.name}}.go": x.tmpl
+`)
+	blueprint := new(dux.Blueprint)
+	if err := app.Store.Get("a", blueprint); err != nil {
+		t.Fatalf("Store.Get: %s", err)
+	}
+	if got, want := blueprint.Description, "Multi-line\ndescription\n"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+	if got, want := blueprint.Files["{{.name}}.go"], "x.tmpl"; got != want {
+		t.Fatalf("Expected template %q, got %q", want, got)
+	}
+}
+
+func TestFileSystemStore_Get_reads_blueprints_written_in_TOML(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.toml", `name = "a"
+description = "From TOML"
+
+[files]
+"{{.name}}.go" = "x.tmpl"
+`)
+	blueprint := new(dux.Blueprint)
+	if err := app.Store.Get("a", blueprint); err != nil {
+		t.Fatalf("Store.Get: %s", err)
+	}
+	if got, want := blueprint.Description, "From TOML"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+}
+
+func TestFileSystemStore_Put_keeps_the_original_format(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	writeFile(t, app.FileSystem, "blueprints/a.yaml", "name: a\n")
+	do(h.DescribeBlueprint("a", "updated"))
+
+	if exists, _ := app.FileSystem.Exists("blueprints/a.json"); exists {
+		t.Fatalf("Expected no JSON file to be created")
+	}
+	blueprint := new(dux.Blueprint)
+	if err := app.Store.Get("a", blueprint); err != nil {
+		t.Fatalf("Store.Get: %s", err)
+	}
+	if got, want := blueprint.Description, "updated"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+}
+
+func TestFileSystemStore_Put_keeps_lowercase_keys(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	writeFile(t, app.FileSystem, "blueprints/a.yaml", "name: a\n")
+	writeFile(t, app.FileSystem, "blueprints/b.toml", `name = "b"`)
+	do(h.DescribeBlueprint("a", "updated"))
+	do(h.DescribeBlueprint("b", "updated"))
+
+	tree, err := h.Tree(app.FileSystem, "blueprints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for filename, expected := range map[string][]string{
+		"a.yaml": {"name: a\n", "description: updated\n"},
+		"b.toml": {"name = \"b\"\n", "description = \"updated\"\n"},
+	} {
+		for _, line := range expected {
+			if !strings.Contains(tree[filename], line) {
+				t.Errorf("Expected %q in %s:\n%s", line, filename, tree[filename])
+			}
+		}
+	}
+}
+
+func TestFileSystemStore_List_deduplicates_ids_across_formats(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.yaml", "name: a\n")
+	writeFile(t, app.FileSystem, "blueprints/a.json", `{"Name":"a"}`)
+	writeFile(t, app.FileSystem, "blueprints/b.toml", `name = "b"`)
+
+	ids, err := app.Store.List("*")
+	if err != nil {
+		t.Fatalf("Store.List: %s", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+}
+
+func TestFileSystemStore_Get_reports_file_name_and_line_of_parse_errors(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "blueprints/a.yaml", "name: a\n\tdescription: x\n")
+	writeFile(t, app.FileSystem, "blueprints/b.json", "{\n\"Name\": \"b\",\n}")
+
+	for id, location := range map[string]string{"a": "blueprints/a.yaml:2", "b": "blueprints/b.json:3"} {
+		err := app.Store.Get(id, new(dux.Blueprint))
+		if _, ok := err.(*dux.DecodeError); !ok {
+			t.Fatalf("Expected *dux.DecodeError for %q, got %#v", id, err)
+		}
+		if !strings.HasPrefix(err.Error(), location) {
+			t.Fatalf("Expected error to start with %q, got %q", location, err)
+		}
+	}
+}
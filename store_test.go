@@ -0,0 +1,129 @@
+package dux_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dhamidi/dux"
+)
+
+func TestFileSystemStore_List_uses_the_provided_file_system(t *testing.T) {
+	fs := dux.NewInMemoryFileSystem()
+	store := dux.NewFileSystemStore("blueprints", fs)
+	store.Put("b", &dux.Blueprint{Name: "b"})
+	store.Put("a", &dux.Blueprint{Name: "a"})
+	f, _ := fs.Create("blueprints/a/templates/x.tmpl")
+	f.Close()
+
+	ids, err := store.List("*")
+	if err != nil {
+		t.Fatalf("store.List: %s", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+}
+
+func TestFileSystemStore_List_matches_wildcards_in_directories(t *testing.T) {
+	for name, fs := range map[string]dux.FileSystem{
+		"in memory": dux.NewInMemoryFileSystem(),
+		"on disk":   dux.NewOnDiskFileSystem(t.TempDir()),
+	} {
+		store := dux.NewFileSystemStore("blueprints", fs)
+		for _, id := range []string{"a/x", "b/x", "b/y", "x", "c/d/x"} {
+			if err := store.Put(id, &dux.Blueprint{Name: id}); err != nil {
+				t.Fatalf("%s: store.Put: %s", name, err)
+			}
+		}
+
+		ids, err := store.List("*/x")
+		if err != nil {
+			t.Fatalf("%s: store.List: %s", name, err)
+		}
+		if expected := []string{"a/x", "b/x"}; !reflect.DeepEqual(ids, expected) {
+			t.Fatalf("%s: Expected %v, got %v", name, expected, ids)
+		}
+	}
+}
+
+func TestFileSystemStore_Delete_and_Exists(t *testing.T) {
+	store := dux.NewFileSystemStore("blueprints", dux.NewInMemoryFileSystem())
+	store.Put("a", &dux.Blueprint{Name: "a"})
+	if exists, _ := store.Exists("a"); !exists {
+		t.Fatalf("Expected %q to exist", "a")
+	}
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("store.Delete: %s", err)
+	}
+	if exists, _ := store.Exists("a"); exists {
+		t.Fatalf("Expected %q to be deleted", "a")
+	}
+	if err := store.Get("a", new(dux.Blueprint)); !dux.IsNotFound(err) {
+		t.Fatalf("Expected *dux.NotFoundError, got %#v", err)
+	}
+}
+
+func TestInMemoryStore_stores_copies_of_objects(t *testing.T) {
+	store := dux.NewInMemoryStore()
+	blueprint := &dux.Blueprint{Name: "a", Description: "before"}
+	store.Put("a", blueprint)
+	blueprint.Description = "after"
+
+	loaded := new(dux.Blueprint)
+	if err := store.Get("a", loaded); err != nil {
+		t.Fatalf("store.Get: %s", err)
+	}
+	if got, want := loaded.Description, "before"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+}
+
+func TestLayeredStore_reads_from_the_first_layer_containing_an_object(t *testing.T) {
+	top, bottom := dux.NewInMemoryStore(), dux.NewInMemoryStore()
+	top.Put("a", &dux.Blueprint{Name: "a", Description: "top"})
+	bottom.Put("a", &dux.Blueprint{Name: "a", Description: "bottom"})
+	bottom.Put("b", &dux.Blueprint{Name: "b", Description: "bottom"})
+	store := dux.NewLayeredStore(top, bottom)
+
+	ids, _ := store.List("*")
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+
+	loaded := new(dux.Blueprint)
+	store.Get("a", loaded)
+	if got, want := loaded.Description, "top"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+
+	store.Delete("a")
+	store.Get("a", loaded)
+	if got, want := loaded.Description, "bottom"; got != want {
+		t.Fatalf("Expected description %q after deleting from top layer, got %q", want, got)
+	}
+}
+
+func TestVersionedStore_keeps_previous_versions(t *testing.T) {
+	store := dux.NewVersionedStore(dux.NewFileSystemStore("blueprints", dux.NewInMemoryFileSystem()))
+	store.Put("a", &dux.Blueprint{Name: "a", Description: "v1"})
+	store.Put("a", &dux.Blueprint{Name: "a", Description: "v2"})
+
+	versions, err := store.Versions("a")
+	if err != nil {
+		t.Fatalf("store.Versions: %s", err)
+	}
+	if expected := []int{1, 2}; !reflect.DeepEqual(versions, expected) {
+		t.Fatalf("Expected versions %v, got %v", expected, versions)
+	}
+
+	loaded := new(dux.Blueprint)
+	store.GetVersion("a", 1, loaded)
+	if got, want := loaded.Description, "v1"; got != want {
+		t.Fatalf("Expected description %q, got %q", want, got)
+	}
+
+	ids, _ := store.List("*")
+	if expected := []string{"a"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+}
@@ -1,10 +1,13 @@
 package dux
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
 	"html/template"
 	"io"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 )
 
@@ -23,8 +26,13 @@ type TemplateEngine interface {
 }
 
 // HTMLTemplateEngine implements TemplateEngine using html/template.
-// It parses all templates in the root directory, but only renders the
-// one specified by the template file name.
+// All templates below the root directory are available to the template
+// being rendered, but only the one specified by the template file name
+// is rendered.  Templates in subdirectories are named by their slash
+// separated path relative to the root.  Binary files are ignored, as
+// are other templates that cannot be parsed.
+//
+// Errors in templates are returned as *TemplateError.
 type HTMLTemplateEngine struct {
 	dir string
 	fs  FileSystem
@@ -46,51 +54,106 @@ func (t *HTMLTemplateEngine) RenderTemplate(out io.Writer, templateName string,
 		context = data[0]
 	}
 
-	templateFiles, err := t.fs.List(t.dir)
+	sources, err := t.readTemplates()
 	if err != nil {
 		return err
 	}
+	templateName = filepath.ToSlash(templateName)
+	source, found := sources[templateName]
+	if !found {
+		return NewFileSystemError("open", filepath.Join(t.dir, templateName), os.ErrNotExist)
+	}
+
+	tmpl, err := template.New(templateName).Funcs(t.TemplateFuncs()).Parse(source)
+	if err != nil {
+		return newTemplateError(err, sources)
+	}
+	for name, source := range sources {
+		if name == templateName {
+			continue
+		}
+		// A broken template is reported when it is rendered itself
+		// and must not prevent rendering the others.
+		tmpl.New(name).Parse(source)
+	}
+
+	if err := tmpl.ExecuteTemplate(out, templateName, context); err != nil {
+		return newTemplateError(err, sources)
+	}
+	return nil
+}
 
-	tmpl := template.New(templateName).Funcs(t.TemplateFuncs())
-	for _, filename := range templateFiles {
-		templateFile, err := t.fs.Open(filepath.Join(t.dir, filename))
+// readTemplates returns the contents of all text files below the
+// root directory by their slash separated path relative to the root.
+func (t *HTMLTemplateEngine) readTemplates() (map[string]string, error) {
+	sources := map[string]string{}
+	err := t.fs.Walk(t.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relative, err := filepath.Rel(t.dir, path)
 		if err != nil {
 			return err
 		}
-		contents, err := ioutil.ReadAll(templateFile)
+		templateFile, err := t.fs.Open(path)
 		if err != nil {
-			templateFile.Close()
 			return err
 		}
-		tmpl, err = tmpl.Parse(string(contents))
+		defer templateFile.Close()
+		reader := bufio.NewReaderSize(templateFile, binarySniffLength)
+		if head, _ := reader.Peek(binarySniffLength); isBinary(head) {
+			return nil
+		}
+		contents, err := ioutil.ReadAll(reader)
 		if err != nil {
 			return err
 		}
+		sources[filepath.ToSlash(relative)] = string(contents)
+		return nil
+	})
+	if err != nil && !IsNotExist(err) {
+		return nil, err
 	}
-
-	return tmpl.ExecuteTemplate(out, templateName, context)
+	return sources, nil
 }
 
 // RenderString implements TemplateEngine
 func (t *HTMLTemplateEngine) RenderString(tmpl string, data interface{}) (string, error) {
 	parsedTemplate, err := template.New("main").Funcs(t.TemplateFuncs()).Parse(tmpl)
 	if err != nil {
-		return tmpl, err
+		return tmpl, anonymousTemplateError(err, tmpl)
 	}
 
 	out := bytes.NewBufferString("")
 	if err := parsedTemplate.Execute(out, data); err != nil {
-		return tmpl, err
+		return tmpl, anonymousTemplateError(err, tmpl)
 	}
 
 	return out.String(), nil
 }
 
+// anonymousTemplateError converts an error in the template string tmpl
+// into a *TemplateError without a template name.
+func anonymousTemplateError(err error, tmpl string) *TemplateError {
+	templateErr := newTemplateError(err, map[string]string{"main": tmpl})
+	templateErr.Template = ""
+	return templateErr
+}
+
 // TemplateFuncs returns a template.FuncMap containing the functions that should be made available to all templates.
 //
 // Modifying the map returned by this functions makes it possible to add more functions to a template.
 func (t *HTMLTemplateEngine) TemplateFuncs() template.FuncMap {
 	return template.FuncMap{
-		"identifier": ParseIdentifier,
+		"identifier": toIdentifier,
+	}
+}
+
+// toIdentifier returns value if it already is an identifier and parses
+// its string representation otherwise.
+func toIdentifier(value interface{}) *Identifier {
+	if identifier, ok := value.(*Identifier); ok {
+		return identifier
 	}
+	return ParseIdentifier(fmt.Sprint(value))
 }
@@ -0,0 +1,112 @@
+package dux
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// snippetContext is the number of lines shown before and after the
+// offending line in the snippet of a TemplateError.
+const snippetContext = 2
+
+// TemplateError is returned when a template cannot be parsed or
+// executed.  It describes where the error occurred and carries a
+// snippet of the lines around it.
+type TemplateError struct {
+	Blueprint   string // The blueprint being rendered, if known
+	Template    string // The template in which the error occurred
+	Destination string // The file being generated, if known
+	Line        int    // The line on which the error occurred, or 0 if unknown
+	Column      int    // The column at which the error occurred, or 0 if unknown
+	Message     string // The error message without its location
+	Snippet     string // The lines of the template around the error
+	Err         error
+}
+
+// Error implements the error interface
+func (err *TemplateError) Error() string {
+	location := err.Template
+	if location == "" {
+		location = "template"
+	}
+	if err.Line > 0 {
+		location += ":" + strconv.Itoa(err.Line)
+	}
+	if err.Column > 0 {
+		location += ":" + strconv.Itoa(err.Column)
+	}
+	context := []string{}
+	if err.Blueprint != "" {
+		context = append(context, "blueprint "+err.Blueprint)
+	}
+	if err.Destination != "" {
+		context = append(context, "generating "+err.Destination)
+	}
+	if len(context) == 0 {
+		return fmt.Sprintf("%s: %s", location, err.Message)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, err.Message, strings.Join(context, ", "))
+}
+
+// Unwrap returns the underlying error.
+func (err *TemplateError) Unwrap() error { return err.Err }
+
+// templateErrorPattern extracts the template name, line, column and
+// message from errors reported by text/template and html/template.
+var templateErrorPattern = regexp.MustCompile(`(?s)^(?:html/)?template: ?([^:]*):(\d+):(?:(\d+):)? ?(.*)$`)
+
+// newTemplateError converts err, as returned by html/template, into a
+// *TemplateError.  The snippet is taken from the source of the
+// template named in err, as found in sources.
+func newTemplateError(err error, sources map[string]string) *TemplateError {
+	result := &TemplateError{Message: err.Error(), Err: err}
+	match := templateErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return result
+	}
+	result.Template = match[1]
+	result.Line, _ = strconv.Atoi(match[2])
+	result.Column, _ = strconv.Atoi(match[3])
+	result.Message = match[4]
+	if source, found := sources[result.Template]; found {
+		result.Snippet = SourceSnippet(source, result.Line, result.Column, snippetContext)
+	}
+	return result
+}
+
+// SourceSnippet returns the lines of source around line, prefixed
+// with their line numbers.  If column is positive, a marker pointing
+// at the column is shown below the offending line.
+func SourceSnippet(source string, line, column, context int) string {
+	lines := strings.Split(source, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := line-context, line+context
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+
+	out := new(strings.Builder)
+	for n := first; n <= last; n++ {
+		text := lines[n-1]
+		fmt.Fprintf(out, "%4d | %s\n", n, text)
+		if n != line || column < 1 || column > len(text)+1 {
+			continue
+		}
+		// Keep tabs so that the marker lines up with the text above
+		padding := []byte(text[:column-1])
+		for i, c := range padding {
+			if c != '\t' {
+				padding[i] = ' '
+			}
+		}
+		fmt.Fprintf(out, "     | %s^\n", padding)
+	}
+	return out.String()
+}
@@ -0,0 +1,72 @@
+package dux
+
+// TestBlueprint renders a blueprint with the inputs of all its test
+// fixtures and compares the results against the expected outputs.
+type TestBlueprint struct {
+	BlueprintName string
+	Update        bool // Replace the expected outputs with the rendered outputs
+}
+
+// CommandName implements Command
+func (c *TestBlueprint) CommandName() string { return "test-blueprint" }
+
+// TestBlueprintWithFixtures runs all test fixtures of a blueprint and
+// emits one event per fixture: "blueprint-fixture-passed",
+// "blueprint-fixture-failed" or, when updating, "blueprint-fixture-updated".
+type TestBlueprintWithFixtures struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+}
+
+// NewTestBlueprintWithFixtures returns a new command handler with the given file system and store.
+func NewTestBlueprintWithFixtures(fs FileSystem, store Store, events EventStore) *TestBlueprintWithFixtures {
+	return &TestBlueprintWithFixtures{
+		fs:     fs,
+		store:  store,
+		events: events,
+	}
+}
+
+// Execute implements CommandHandler
+func (h *TestBlueprintWithFixtures) Execute(command Command) error {
+	args := command.(*TestBlueprint)
+	fixtures, err := BlueprintFixtures(h.fs, args.BlueprintName)
+	if err != nil {
+		return err
+	}
+
+	for _, fixture := range fixtures {
+		result, err := RunBlueprintFixture(h.fs, h.store, args.BlueprintName, fixture)
+		if err != nil {
+			return err
+		}
+		payload := EventPayload{
+			"blueprintName": args.BlueprintName,
+			"fixture":       fixture,
+			"result":        result,
+		}
+
+		switch {
+		case args.Update && len(result.Errors) == 0:
+			if err := UpdateBlueprintFixture(h.fs, args.BlueprintName, result); err != nil {
+				return err
+			}
+			h.events.Emit(&Event{Name: "blueprint-fixture-updated", Payload: payload})
+		case result.Passed():
+			h.events.Emit(&Event{Name: "blueprint-fixture-passed", Payload: payload})
+		default:
+			payload["diff"] = result.Diff()
+			h.events.Emit(&Event{Name: "blueprint-fixture-failed", Payload: payload})
+		}
+	}
+
+	h.events.Emit(&Event{
+		Name: "blueprint-tested",
+		Payload: EventPayload{
+			"name":     args.BlueprintName,
+			"fixtures": len(fixtures),
+		},
+	})
+	return nil
+}
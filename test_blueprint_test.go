@@ -0,0 +1,73 @@
+package dux_test
+
+import (
+	"testing"
+
+	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
+)
+
+func newBlueprintWithFixture(t *testing.T, expected string) *dux.Application {
+	t.Helper()
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "Hello, {{.name}}!\n"))
+	do(h.DefineBlueprintFile("a", "{{.name}}.txt", "x.tmpl"))
+	writeFile(t, app.FileSystem, "blueprints/a/testdata/world/input.yaml", "name: world\n")
+	writeFile(t, app.FileSystem, "blueprints/a/testdata/world/expected/world.txt", expected)
+	return app
+}
+
+func TestBlueprintFixtures_can_be_asserted_from_go_test(t *testing.T) {
+	app := newBlueprintWithFixture(t, "Hello, world!\n")
+	h.AssertBlueprintFixtures(t, app, "a")
+}
+
+func TestTestBlueprintWithFixtures_emits_an_event_with_a_diff_for_failing_fixtures(t *testing.T) {
+	app := newBlueprintWithFixture(t, "Hello, you!\n")
+	do := h.FailOnExecuteError(t, app)
+	do(h.TestBlueprint("a", false))
+	h.AssertEvent(t, app.EventStore, "blueprint-fixture-failed", dux.EventPayload{
+		"fixture": "world",
+		"diff": `--- expected/world.txt
++++ rendered/world.txt
+@@ -1,1 +1,1 @@
+-Hello, you!
++Hello, world!
+`,
+	})
+}
+
+func TestTestBlueprintWithFixtures_rewrites_expected_files_when_updating(t *testing.T) {
+	app := newBlueprintWithFixture(t, "Hello, you!\n")
+	writeFile(t, app.FileSystem, "blueprints/a/testdata/world/expected/stale.txt", "stale")
+	do := h.FailOnExecuteError(t, app)
+	do(h.TestBlueprint("a", true))
+	h.AssertEvent(t, app.EventStore, "blueprint-fixture-updated", dux.EventPayload{"fixture": "world"})
+	h.AssertFileContents(t, app.FileSystem, "blueprints/a/testdata/world/expected/world.txt", "Hello, world!\n")
+	if exists, _ := app.FileSystem.Exists("blueprints/a/testdata/world/expected/stale.txt"); exists {
+		t.Fatalf("Expected stale golden file to be removed")
+	}
+	h.AssertBlueprintFixtures(t, app, "a")
+}
+
+func TestBlueprintFixtures_render_the_skeleton_of_the_blueprint(t *testing.T) {
+	app := newBlueprintWithFixture(t, "Hello, world!\n")
+	h.WriteTree(t, app.FileSystem, "blueprints/a/skeleton", map[string]string{"{{.name}}/README": "About {{.name}}\n"})
+	writeFile(t, app.FileSystem, "blueprints/a/testdata/world/expected/world/README", "About world\n")
+	h.AssertBlueprintFixtures(t, app, "a")
+}
+
+func TestBlueprintFixtures_use_default_values_and_argument_types(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DefineBlueprintArgument("a", &dux.Argument{Name: "name", Type: dux.ArgumentTypeIdentifier, Required: true}))
+	do(h.DefineBlueprintArgument("a", &dux.Argument{Name: "greeting", Default: "Hello"}))
+	do(h.DefineBlueprintTemplate("a", "x.tmpl", "{{.greeting}}, {{.name.ToCamel.Title}}!\n"))
+	do(h.DefineBlueprintFile("a", "{{.name.ToSnake}}.txt", "x.tmpl"))
+	writeFile(t, app.FileSystem, "blueprints/a/testdata/world/input.yaml", "name: big-world\n")
+	writeFile(t, app.FileSystem, "blueprints/a/testdata/world/expected/big_world.txt", "Hello, BigWorld!\n")
+	h.AssertBlueprintFixtures(t, app, "a")
+}
@@ -0,0 +1,38 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/dhamidi/dux"
+)
+
+// AssertBlueprintFixtures runs every test fixture of the named
+// blueprint in app as a subtest of t, failing the subtest with a diff
+// if the rendered files do not match the expected files.
+func AssertBlueprintFixtures(t *testing.T, app *dux.Application, blueprintName string) {
+	t.Helper()
+	fixtures, err := dux.BlueprintFixtures(app.FileSystem, blueprintName)
+	if err != nil {
+		t.Fatalf("AssertBlueprintFixtures: %s", err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatalf("AssertBlueprintFixtures: no fixtures found for blueprint %q", blueprintName)
+	}
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		t.Run(fixture, func(t *testing.T) {
+			t.Helper()
+			result, err := dux.RunBlueprintFixture(app.FileSystem, app.Store, blueprintName, fixture)
+			if err != nil {
+				t.Fatalf("AssertBlueprintFixtures: %s", err)
+			}
+			for _, err := range result.Errors {
+				t.Errorf("Rendering failed: %s", err)
+			}
+			if diff := result.Diff(); diff != "" {
+				t.Errorf("Rendered files do not match expected files:\n%s", diff)
+			}
+		})
+	}
+}
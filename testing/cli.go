@@ -0,0 +1,47 @@
+package testing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dhamidi/dux"
+	"github.com/dhamidi/dux/cli"
+)
+
+// CLIResult captures the outcome of running a CLI command.
+type CLIResult struct {
+	Stdout     string
+	Stderr     string
+	ExitStatus int
+}
+
+// RunCLI runs cmd with args in app, feeding stdin to the command and
+// capturing everything the command writes.
+//
+// Events and errors are reported just like the dux executable does
+// and the exit status is the one the dux executable would use.
+func RunCLI(app *dux.Application, cmd cli.Command, stdin string, args ...string) *CLIResult {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	ctx := cli.NewCLI(app,
+		cli.WithInput(strings.NewReader(stdin)),
+		cli.WithOutput(stdout),
+		cli.WithErrorOutput(stderr),
+	)
+
+	exitStatus := ctx.Run(cmd, args)
+	return &CLIResult{
+		Stdout:     stdout.String(),
+		Stderr:     stderr.String(),
+		ExitStatus: exitStatus,
+	}
+}
+
+// AssertExitStatus fails t if result did not exit with expected,
+// showing what the command wrote to stderr.
+func AssertExitStatus(t *testing.T, result *CLIResult, expected int) {
+	t.Helper()
+	if result.ExitStatus != expected {
+		t.Fatalf("Expected exit status %d, got %d: %s", expected, result.ExitStatus, result.Stderr)
+	}
+}
@@ -0,0 +1,44 @@
+package testing
+
+import (
+	"fmt"
+	"time"
+)
+
+// FakeClock is a dux.Clock which only moves when told to.
+type FakeClock struct {
+	now time.Time
+}
+
+// NewFakeClock returns a clock that is stopped at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now implements dux.Clock
+func (c *FakeClock) Now() time.Time { return c.now }
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// Set stops the clock at now.
+func (c *FakeClock) Set(now time.Time) { c.now = now }
+
+// SequentialIDGenerator is a dux.IDGenerator returning predictable
+// IDs of the form "<prefix>-1", "<prefix>-2", …
+type SequentialIDGenerator struct {
+	prefix string
+	next   int
+}
+
+// NewSequentialIDGenerator returns a generator starting at "<prefix>-1".
+func NewSequentialIDGenerator(prefix string) *SequentialIDGenerator {
+	return &SequentialIDGenerator{prefix: prefix, next: 1}
+}
+
+// NewID implements dux.IDGenerator
+func (g *SequentialIDGenerator) NewID() string {
+	id := fmt.Sprintf("%s-%d", g.prefix, g.next)
+	g.next++
+	return id
+}
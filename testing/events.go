@@ -0,0 +1,87 @@
+package testing
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dhamidi/dux"
+)
+
+// Events returns all events named eventName in the order in which they
+// have been emitted.
+func Events(t *testing.T, events dux.EventStore, eventName string) []*dux.Event {
+	t.Helper()
+	result := []*dux.Event{}
+	for _, event := range allEvents(t, events) {
+		if event.Name == eventName {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// AssertEventSequence asserts that events with the given names have
+// been emitted in the given order.  Other events may have been emitted
+// before, in between or after them.
+func AssertEventSequence(t *testing.T, events dux.EventStore, eventNames ...string) {
+	t.Helper()
+	emitted := eventNamesOf(allEvents(t, events))
+	next := 0
+	for _, name := range emitted {
+		if next < len(eventNames) && name == eventNames[next] {
+			next++
+		}
+	}
+
+	if next < len(eventNames) {
+		t.Fatalf("Event sequence %v not found in %v: missing %q", eventNames, emitted, eventNames[next])
+	}
+}
+
+// AssertEventCount asserts that exactly count events named eventName
+// have been emitted.
+func AssertEventCount(t *testing.T, events dux.EventStore, eventName string, count int) {
+	t.Helper()
+	if actual := len(Events(t, events, eventName)); actual != count {
+		t.Fatalf("Expected %d %q events, got %d in %v",
+			count, eventName, actual, eventNamesOf(allEvents(t, events)))
+	}
+}
+
+// AssertNoEvent asserts that no event named eventName has been
+// emitted whose payload contains all entries of expectedPayload.  A
+// nil payload matches any event with that name.
+func AssertNoEvent(t *testing.T, events dux.EventStore, eventName string, expectedPayload dux.EventPayload) {
+	t.Helper()
+	for _, event := range Events(t, events, eventName) {
+		if payloadMatches(event.Payload, expectedPayload) {
+			t.Fatalf("Unexpected event %q with payload %v", eventName, event.Payload)
+		}
+	}
+}
+
+func allEvents(t *testing.T, events dux.EventStore) []*dux.Event {
+	t.Helper()
+	all, err := events.All()
+	if err != nil {
+		t.Fatalf("failed to fetch events from event store: %s", err)
+	}
+	return all
+}
+
+func eventNamesOf(events []*dux.Event) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Name
+	}
+	return names
+}
+
+func payloadMatches(actual, expected dux.EventPayload) bool {
+	for key, expectedValue := range expected {
+		if !reflect.DeepEqual(expectedValue, actual[key]) {
+			return false
+		}
+	}
+	return true
+}
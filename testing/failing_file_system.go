@@ -1,12 +1,17 @@
 package testing
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/dhamidi/dux"
 )
 
+// errFailure is the error wrapped by the *dux.FileSystemError returned
+// for failing actions.
+var errFailure = errors.New("simulated failure")
+
 // FailingFileSystem wraps filesystem and optionally fails for
 // preconfigured actions and paths.
 type FailingFileSystem struct {
@@ -23,16 +28,18 @@ func NewFailingFileSystem(fs dux.FileSystem) *FailingFileSystem {
 	}
 }
 
-// Fail causes the given action to fail for the given filename with a generic error.
+// Fail causes the given action to fail for the given filename with a
+// *dux.FileSystemError wrapping a generic error.
 //
-// Valid values for action are "open", "create" and "write".
+// Valid values for action are "open", "create", "write", "rename" and "remove".
 //
 // - "open" causes an error to be returned when a file is Open()'d in the underlying file system
 // - "create" causes an error to be returned when a file is Create()'d in the underlying file system
 // - "write" causes an error when writing to the writer returned by Create()
 // - "rename" causes an error when renaming filename
+// - "remove" causes an error when removing filename
 func (fs *FailingFileSystem) Fail(action string, filename string) {
-	if action != "open" && action != "create" && action != "write" && action != "rename" {
+	if action != "open" && action != "create" && action != "write" && action != "rename" && action != "remove" {
 		panic(fmt.Sprintf("Invalid action supplied to %T.Fail: %q", fs, action))
 	}
 	fs.failures[action] = append(fs.failures[action], filename)
@@ -42,7 +49,7 @@ func (fs *FailingFileSystem) Fail(action string, filename string) {
 func (fs *FailingFileSystem) Create(filename string) (io.WriteCloser, error) {
 	for _, f := range fs.failures["create"] {
 		if f == filename {
-			return nil, fmt.Errorf("Failed to create file %q", filename)
+			return nil, dux.NewFileSystemError("create", filename, errFailure)
 		}
 	}
 
@@ -63,7 +70,7 @@ func (fs *FailingFileSystem) Create(filename string) (io.WriteCloser, error) {
 func (fs *FailingFileSystem) Open(filename string) (io.ReadCloser, error) {
 	for _, f := range fs.failures["open"] {
 		if f == filename {
-			return nil, fmt.Errorf("Failed to open file %q", filename)
+			return nil, dux.NewFileSystemError("open", filename, errFailure)
 		}
 	}
 
@@ -74,9 +81,20 @@ func (fs *FailingFileSystem) Open(filename string) (io.ReadCloser, error) {
 func (fs *FailingFileSystem) Rename(oldpath, newpath string) error {
 	for _, f := range fs.failures["rename"] {
 		if f == oldpath {
-			return fmt.Errorf("Failed to rename %q to %q", oldpath, newpath)
+			return dux.NewFileSystemError("rename", oldpath, fmt.Errorf("%s to %q", errFailure, newpath))
 		}
 	}
 
 	return fs.FileSystem.Rename(oldpath, newpath)
 }
+
+// Remove forwards the call to the underlying FileSystem, unless a failure has been registered for removing the given file.
+func (fs *FailingFileSystem) Remove(filename string) error {
+	for _, f := range fs.failures["remove"] {
+		if f == filename {
+			return dux.NewFileSystemError("remove", filename, errFailure)
+		}
+	}
+
+	return fs.FileSystem.Remove(filename)
+}
@@ -2,6 +2,7 @@ package testing
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
 
@@ -12,6 +13,38 @@ func NewApp() *dux.Application {
 	return dux.NewApplication()
 }
 
+// SetupApp returns a new application using fs after executing
+// commands in order, failing t if any of them fails.  A nil fs
+// selects an in-memory file system.
+func SetupApp(t *testing.T, fs dux.FileSystem, commands ...dux.Command) *dux.Application {
+	t.Helper()
+	app := NewApp()
+	if fs != nil {
+		app.FileSystem = fs
+		app.Init()
+	}
+	for _, command := range commands {
+		if err := app.Execute(command); err != nil {
+			t.Fatalf("%s: %s", command.CommandName(), err)
+		}
+	}
+	return app
+}
+
+// NewAppWithBlueprint returns an application using fs with a
+// blueprint named "a", which generates "{{ .name }}.txt" from the
+// template "greeting.txt" with the given contents.  The commands are
+// executed after the blueprint has been defined.
+func NewAppWithBlueprint(t *testing.T, fs dux.FileSystem, contents string, commands ...dux.Command) *dux.Application {
+	t.Helper()
+	return SetupApp(t, fs, append([]dux.Command{
+		CreateBlueprint("a"),
+		DescribeBlueprint("a", "A test"),
+		DefineBlueprintTemplate("a", "greeting.txt", contents),
+		DefineBlueprintFile("a", "{{ .name }}.txt", "greeting.txt"),
+	}, commands...)...)
+}
+
 func ExampleBlueprintName() string {
 	return "test"
 }
@@ -41,6 +74,20 @@ func DescribeBlueprint(name string, description string) *dux.DescribeBlueprint {
 	}
 }
 
+func DefineBlueprintArgument(blueprintName string, argument *dux.Argument) *dux.DefineBlueprintArgument {
+	return &dux.DefineBlueprintArgument{
+		BlueprintName: blueprintName,
+		Argument:      argument,
+	}
+}
+
+func DefineBlueprintHook(blueprintName, command string) *dux.DefineBlueprintHook {
+	return &dux.DefineBlueprintHook{
+		BlueprintName: blueprintName,
+		Command:       command,
+	}
+}
+
 func Install(pairs ...string) *dux.Install {
 	sources := []string{}
 	destinations := []string{}
@@ -77,6 +124,31 @@ func DefineBlueprintFile(blueprintName, fileName, templateName string) *dux.Defi
 	}
 }
 
+func MigrateBlueprints() *dux.MigrateBlueprints {
+	return &dux.MigrateBlueprints{}
+}
+
+func ExportBlueprint(name string, destination io.Writer) *dux.ExportBlueprint {
+	return &dux.ExportBlueprint{
+		BlueprintName: name,
+		Destination:   destination,
+	}
+}
+
+func ImportBlueprint(source io.Reader, force bool) *dux.ImportBlueprint {
+	return &dux.ImportBlueprint{
+		Source: source,
+		Force:  force,
+	}
+}
+
+func TestBlueprint(name string, update bool) *dux.TestBlueprint {
+	return &dux.TestBlueprint{
+		BlueprintName: name,
+		Update:        update,
+	}
+}
+
 func FailOnExecuteError(t *testing.T, h dux.CommandHandler) func(dux.Command) error {
 	return func(cmd dux.Command) error {
 		if err := h.Execute(cmd); err != nil {
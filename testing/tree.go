@@ -0,0 +1,110 @@
+package testing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/dhamidi/dux"
+)
+
+// Tree reads all files below root in fs, returning their contents
+// keyed by their path relative to root.
+//
+// A missing root results in an empty tree.
+func Tree(fs dux.FileSystem, root string) (map[string]string, error) {
+	tree := map[string]string{}
+	err := fs.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == filepath.Clean(root) && dux.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		in, err := fs.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		contents, err := ioutil.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		tree[filepath.ToSlash(relative)] = string(contents)
+		return nil
+	})
+	return tree, err
+}
+
+// AssertTree asserts that the files below root in fs are exactly the
+// files in expected, which maps slash separated paths relative to root
+// to file contents.
+func AssertTree(t *testing.T, fs dux.FileSystem, root string, expected map[string]string) {
+	t.Helper()
+	actual, err := Tree(fs, root)
+	if err != nil {
+		t.Fatalf("AssertTree: %s", err)
+	}
+
+	paths := map[string]bool{}
+	for path := range expected {
+		paths[path] = true
+	}
+	for path := range actual {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	message := new(bytes.Buffer)
+	for _, path := range sorted {
+		expectedContents, isExpected := expected[path]
+		actualContents, isPresent := actual[path]
+		switch {
+		case !isPresent:
+			fmt.Fprintf(message, "missing file %s\n", path)
+		case !isExpected:
+			fmt.Fprintf(message, "unexpected file %s\n", path)
+		case expectedContents != actualContents:
+			fmt.Fprintf(message, "%s", dux.Diff("expected/"+path, "actual/"+path, expectedContents, actualContents))
+		}
+	}
+
+	if message.Len() > 0 {
+		t.Fatalf("Tree %q does not match:\n%s", root, message)
+	}
+}
+
+// WriteTree creates the files in tree below root in fs.  Like in
+// AssertTree, tree maps slash separated paths relative to root to file
+// contents.
+func WriteTree(t *testing.T, fs dux.FileSystem, root string, tree map[string]string) {
+	t.Helper()
+	for path, contents := range tree {
+		filename := filepath.Join(root, filepath.FromSlash(path))
+		f, err := fs.Create(filename)
+		if err != nil {
+			t.Fatalf("WriteTree: %s", err)
+		}
+		_, err = io.WriteString(f, contents)
+		f.Close()
+		if err != nil {
+			t.Fatalf("WriteTree: %s", err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package dux_test
+
+import (
+	"testing"
+
+	h "github.com/dhamidi/dux/testing"
+)
+
+func TestTree_returns_files_relative_to_root(t *testing.T) {
+	app := h.NewApp()
+	writeFile(t, app.FileSystem, "out/a.txt", "a")
+	writeFile(t, app.FileSystem, "out/sub/b.txt", "b")
+	writeFile(t, app.FileSystem, "other/c.txt", "c")
+
+	h.AssertTree(t, app.FileSystem, "out", map[string]string{
+		"a.txt":     "a",
+		"sub/b.txt": "b",
+	})
+}
+
+func TestTree_treats_a_missing_root_as_empty(t *testing.T) {
+	app := h.NewApp()
+	h.AssertTree(t, app.FileSystem, "missing", map[string]string{})
+}
+
+func TestAssertEventSequence_allows_events_in_between(t *testing.T) {
+	app := h.NewApp()
+	do := h.FailOnExecuteError(t, app)
+	do(h.CreateBlueprint("a"))
+	do(h.DescribeBlueprint("a", "first"))
+	do(h.DescribeBlueprint("a", "second"))
+
+	h.AssertEventSequence(t, app.EventStore, "blueprint-created", "blueprint-description-set")
+	h.AssertEventCount(t, app.EventStore, "blueprint-description-set", 2)
+	h.AssertNoEvent(t, app.EventStore, "blueprint-description-set", map[string]interface{}{"description": "third"})
+}
@@ -4,15 +4,32 @@ package dux
 type TransientEventStore struct {
 	events      []*Event
 	subscribers []func(*Event)
+	clock       Clock
+	ids         IDGenerator
 }
 
-// NewTransientEventStore creates an empty transient event store.
+// NewTransientEventStore creates an empty transient event store,
+// stamping events with the system time and random IDs.
 func NewTransientEventStore() *TransientEventStore {
 	return &TransientEventStore{
 		events: []*Event{},
+		clock:  SystemClock{},
+		ids:    RandomIDGenerator{},
 	}
 }
 
+// WithClock sets the clock used for stamping emitted events.
+func (s *TransientEventStore) WithClock(clock Clock) *TransientEventStore {
+	s.clock = clock
+	return s
+}
+
+// WithIDGenerator sets the generator used for assigning IDs to emitted events.
+func (s *TransientEventStore) WithIDGenerator(ids IDGenerator) *TransientEventStore {
+	s.ids = ids
+	return s
+}
+
 // All returns all events that have been emitted so far.
 //
 // It never returns an error.
@@ -20,10 +37,19 @@ func (s *TransientEventStore) All() ([]*Event, error) {
 	return s.events, nil
 }
 
-// Emit records events
+// Emit records events, assigning an ID and time to events which do
+// not have one yet.
 //
 // It never returns an error.
 func (s *TransientEventStore) Emit(events ...*Event) error {
+	for _, event := range events {
+		if event.ID == "" {
+			event.ID = s.ids.NewID()
+		}
+		if event.Time.IsZero() {
+			event.Time = s.clock.Now()
+		}
+	}
 	s.events = append(s.events, events...)
 	for _, event := range events {
 		s.Notify(event)
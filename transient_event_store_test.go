@@ -2,8 +2,10 @@ package dux_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/dhamidi/dux"
+	h "github.com/dhamidi/dux/testing"
 )
 
 func TestTransientEventStore_Emit_notifies_subscribers(t *testing.T) {
@@ -19,3 +21,20 @@ func TestTransientEventStore_Emit_notifies_subscribers(t *testing.T) {
 		t.Fatal("Subscriber not notified")
 	}
 }
+
+func TestTransientEventStore_Emit_stamps_events_with_id_and_time(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	eventStore := dux.NewTransientEventStore().
+		WithClock(h.NewFakeClock(now)).
+		WithIDGenerator(h.NewSequentialIDGenerator("event"))
+
+	eventStore.Emit(&dux.Event{Name: "first"}, &dux.Event{Name: "second", ID: "custom"})
+
+	events, _ := eventStore.All()
+	if events[0].ID != "event-1" || events[1].ID != "custom" {
+		t.Fatalf("Unexpected IDs: %q, %q", events[0].ID, events[1].ID)
+	}
+	if !events[0].Time.Equal(now) {
+		t.Fatalf("Expected event time %s, got %s", now, events[0].Time)
+	}
+}
@@ -0,0 +1,191 @@
+package dux
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// UpdateBlueprints updates blueprints installed from git
+// repositories to the latest commit of the ref they have been
+// installed from.
+type UpdateBlueprints struct {
+	Names []string // The blueprints to update; all blueprints if empty
+}
+
+// CommandName implements Command
+func (c *UpdateBlueprints) CommandName() string { return "update-blueprints" }
+
+// UpdateFailedError is returned when one or more blueprints could not
+// be updated, e.g. because their source could not be fetched.
+type UpdateFailedError struct {
+	Errors []error // The errors of all failed sources and blueprints
+}
+
+// Error implements the error interface
+func (err *UpdateFailedError) Error() string {
+	return fmt.Sprintf("Failed to update blueprints:\n%s", IndentErrors(err.Errors))
+}
+
+// UpdateBlueprintsFromGit clones the origin of every blueprint and
+// replaces blueprints for which a newer commit is available.
+//
+// For every changed template a "blueprint-template-changed" event
+// carrying a unified diff of the change is emitted.
+//
+// A failure to update from one source does not stop the others from
+// being updated.  All failures are returned as an *UpdateFailedError.
+type UpdateBlueprintsFromGit struct {
+	fs     FileSystem
+	store  Store
+	events EventStore
+	git    *Git
+}
+
+// NewUpdateBlueprintsFromGit returns a new command handler using the git executable found in $PATH.
+func NewUpdateBlueprintsFromGit(fs FileSystem, store Store, events EventStore) *UpdateBlueprintsFromGit {
+	return &UpdateBlueprintsFromGit{
+		fs:     fs,
+		store:  store,
+		events: events,
+		git:    NewGit(),
+	}
+}
+
+// Execute implements CommandHandler
+func (h *UpdateBlueprintsFromGit) Execute(command Command) error {
+	args := command.(*UpdateBlueprints)
+	names := args.Names
+	if len(names) == 0 {
+		all, err := h.store.List("*")
+		if err != nil {
+			return err
+		}
+		names = all
+	}
+
+	sources := []GitSource{}
+	blueprintsBySource := map[GitSource][]*Blueprint{}
+	for _, name := range names {
+		blueprint := new(Blueprint)
+		if err := h.store.Get(name, blueprint); err != nil {
+			return err
+		}
+		if blueprint.Origin == nil {
+			continue
+		}
+		source := GitSource{URL: blueprint.Origin.URL, Ref: blueprint.Origin.Ref}
+		if _, seen := blueprintsBySource[source]; !seen {
+			sources = append(sources, source)
+		}
+		blueprintsBySource[source] = append(blueprintsBySource[source], blueprint)
+	}
+
+	failures := []error{}
+	for _, source := range sources {
+		failures = append(failures, h.updateFrom(&source, blueprintsBySource[source])...)
+	}
+
+	if len(failures) > 0 {
+		return &UpdateFailedError{Errors: failures}
+	}
+	return nil
+}
+
+// updateFrom updates all blueprints from a single source and returns
+// the errors that occurred.
+func (h *UpdateBlueprintsFromGit) updateFrom(source *GitSource, blueprints []*Blueprint) []error {
+	checkout, err := checkoutBlueprints(h.git, source)
+	if err != nil {
+		for _, blueprint := range blueprints {
+			h.fail(blueprint.Name, err)
+		}
+		return []error{fmt.Errorf("%s: %s", source, err)}
+	}
+	defer checkout.Close()
+
+	failures := []error{}
+
+	for _, blueprint := range blueprints {
+		if blueprint.Origin.Commit == checkout.commit {
+			h.events.Emit(&Event{
+				Name: "blueprint-up-to-date",
+				Payload: EventPayload{
+					"name":   blueprint.Name,
+					"commit": checkout.commit,
+				},
+			})
+			continue
+		}
+		if err := h.update(checkout, source, blueprint); err != nil {
+			h.fail(blueprint.Name, err)
+			failures = append(failures, fmt.Errorf("%s: %s", blueprint.Name, err))
+		}
+	}
+	return failures
+}
+
+// update replaces blueprint and its templates with the version found in checkout.
+func (h *UpdateBlueprintsFromGit) update(checkout *blueprintCheckout, source *GitSource, blueprint *Blueprint) error {
+	updated := new(Blueprint)
+	if err := checkout.store.Get(blueprint.Name, updated); err != nil {
+		return err
+	}
+	oldTemplates, err := readTemplates(h.fs, blueprint.Name)
+	if err != nil {
+		return err
+	}
+	newTemplates, err := readTemplates(checkout.fs, blueprint.Name)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range templateNames(oldTemplates, newTemplates) {
+		path := filepath.ToSlash(filepath.Join(BlueprintTemplateDirectory(blueprint.Name), name))
+		diff := Diff("a/"+path, "b/"+path, oldTemplates[name], newTemplates[name])
+		if diff == "" {
+			continue
+		}
+		h.events.Emit(&Event{
+			Name: "blueprint-template-changed",
+			Payload: EventPayload{
+				"blueprintName": blueprint.Name,
+				"template":      name,
+				"diff":          diff,
+			},
+		})
+	}
+
+	if err := replaceTemplates(h.fs, blueprint.Name, newTemplates); err != nil {
+		return err
+	}
+	if err := copySkeleton(checkout.fs, h.fs, blueprint.Name); err != nil {
+		return err
+	}
+	updated.Origin = &BlueprintOrigin{
+		URL:    source.URL,
+		Ref:    source.Ref,
+		Commit: checkout.commit,
+	}
+	if err := h.store.Put(blueprint.Name, updated); err != nil {
+		return err
+	}
+
+	h.events.Emit(&Event{
+		Name: "blueprint-updated",
+		Payload: EventPayload{
+			"name": blueprint.Name,
+			"from": blueprint.Origin.Commit,
+			"to":   checkout.commit,
+		},
+	})
+	return nil
+}
+
+// fail emits an event about a blueprint that could not be updated.
+func (h *UpdateBlueprintsFromGit) fail(name string, err error) {
+	h.events.Emit(&Event{
+		Name:    "blueprint-update-failed",
+		Error:   err,
+		Payload: EventPayload{"name": name},
+	})
+}
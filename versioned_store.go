@@ -0,0 +1,106 @@
+package dux
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionsPrefix is the id prefix under which a VersionedStore keeps
+// the history of objects.
+const versionsPrefix = ".versions"
+
+// VersionedStore implements Store by wrapping another store and
+// keeping a copy of every object written with Put.
+//
+// Versions are numbered consecutively starting at 1 and are stored
+// in the wrapped store under ids starting with ".versions/".  These
+// ids are hidden from List.
+type VersionedStore struct {
+	store Store
+}
+
+// NewVersionedStore wraps store to record every version of an object.
+func NewVersionedStore(store Store) *VersionedStore {
+	return &VersionedStore{
+		store: store,
+	}
+}
+
+// versionID returns the id under which the given version of the object identified by id is stored.
+func (s *VersionedStore) versionID(id string, version int) string {
+	return path.Join(versionsPrefix, id, strconv.Itoa(version))
+}
+
+// Get loads the latest version of the object identified by id.
+func (s *VersionedStore) Get(id string, dest interface{}) error {
+	return s.store.Get(id, dest)
+}
+
+// GetVersion loads a specific version of the object identified by id.
+func (s *VersionedStore) GetVersion(id string, version int, dest interface{}) error {
+	return s.store.Get(s.versionID(id, version), dest)
+}
+
+// Versions returns the numbers of all recorded versions of the object identified by id in ascending order.
+func (s *VersionedStore) Versions(id string) ([]int, error) {
+	ids, err := s.store.List(path.Join(versionsPrefix, id, "*"))
+	if err != nil {
+		return []int{}, err
+	}
+
+	result := []int{}
+	for _, versionID := range ids {
+		version, err := strconv.Atoi(path.Base(versionID))
+		if err != nil {
+			continue
+		}
+		result = append(result, version)
+	}
+	sort.Ints(result)
+	return result, nil
+}
+
+// Put stores src as the latest version of the object identified by id.
+func (s *VersionedStore) Put(id string, src interface{}) error {
+	versions, err := s.Versions(id)
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(versions) > 0 {
+		next = versions[len(versions)-1] + 1
+	}
+	if err := s.store.Put(s.versionID(id, next), src); err != nil {
+		return err
+	}
+	return s.store.Put(id, src)
+}
+
+// List returns the ids matching pattern, excluding the ids used for storing versions.
+func (s *VersionedStore) List(pattern string) ([]string, error) {
+	ids, err := s.store.List(pattern)
+	if err != nil {
+		return ids, err
+	}
+	result := []string{}
+	for _, id := range ids {
+		if id == versionsPrefix || strings.HasPrefix(id, versionsPrefix+"/") {
+			continue
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// Delete removes the object identified by id.  Previous versions
+// of the object are kept.
+func (s *VersionedStore) Delete(id string) error {
+	return s.store.Delete(id)
+}
+
+// Exists returns true if the wrapped store contains the object identified by id.
+func (s *VersionedStore) Exists(id string) (bool, error) {
+	return s.store.Exists(id)
+}